@@ -0,0 +1,103 @@
+package daleks
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+// Board text is written here when there is no clipboard, and read back
+// from here when importing without one
+const boardFileName = "board.txt"
+
+// clipboardResult is the outcome of reading the clipboard
+type clipboardResult struct {
+	text string
+	err  error
+}
+
+// copyBoard copies the board as text to the clipboard, or to the board
+// file in the data directory when there is no clipboard
+func (g *Game) copyBoard() {
+	board := engine.FormatASCII(g.board)
+	err := writeClipboard(board)
+	if err == nil {
+		g.showNotice("Board copied to the clipboard", 2)
+		return
+	}
+	log.Printf("could not copy the board: %v", err)
+
+	path, err := dataPath(boardFileName)
+	if err == nil {
+		err = os.WriteFile(path, []byte(board), 0o644)
+	}
+	if err != nil {
+		log.Printf("could not save the board: %v", err)
+		g.showNotice("Could not copy the board", 2)
+		return
+	}
+	g.showNotice("No clipboard: board saved to "+boardFileName+" in the data folder", 3)
+}
+
+// importBoard starts reading a board from the clipboard
+func (g *Game) importBoard() {
+	g.paste = readClipboard()
+	g.menuMessage = "Reading the clipboard..."
+}
+
+// updatePaste imports the board once the clipboard has been read, falling
+// back to the board file when there is no clipboard
+func (g *Game) updatePaste() {
+	var result clipboardResult
+	select {
+	case result = <-g.paste:
+		g.paste = nil
+	default:
+		return
+	}
+
+	if result.err != nil {
+		log.Printf("could not read the clipboard: %v", result.err)
+		path, err := dataPath(boardFileName)
+		if err == nil {
+			var data []byte
+			data, err = os.ReadFile(path)
+			result = clipboardResult{text: string(data), err: err}
+		}
+		if err != nil {
+			g.menuMessage = "Could not read the clipboard or " + boardFileName
+			return
+		}
+	}
+
+	if err := g.loadBoard(result.text); err != nil {
+		g.menuMessage = "Could not import board: " + err.Error()
+	}
+}
+
+// loadBoard starts playing a board read from text with the menu's rules.
+// Imported games are not recorded, as their replays could not recreate
+// the board.
+func (g *Game) loadBoard(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return errors.New("no board text")
+	}
+
+	board, err := engine.ParseASCII(text, g.presets[g.preset].Rules)
+	if err != nil {
+		return err
+	}
+	if board.Rules.Width > maxBoardWidth || board.Rules.Height > maxBoardHeight {
+		return fmt.Errorf("a %dx%d board does not fit the window (at most %dx%d)",
+			board.Rules.Width, board.Rules.Height, maxBoardWidth, maxBoardHeight)
+	}
+
+	g.resetBoard(board, nil)
+	g.syncBoard()
+	g.imported = true
+	return nil
+}
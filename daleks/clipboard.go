@@ -0,0 +1,66 @@
+//go:build !js
+
+package daleks
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommand is a program that copies stdin to the clipboard or
+// pastes the clipboard to stdout
+type clipboardCommand []string
+
+// clipboardCommands returns the programs to try for copying and pasting
+// on this platform, in order of preference
+func clipboardCommands() (copiers, pasters []clipboardCommand) {
+	switch runtime.GOOS {
+	case "windows":
+		return []clipboardCommand{{"clip"}},
+			[]clipboardCommand{{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"}}
+	case "darwin":
+		return []clipboardCommand{{"pbcopy"}}, []clipboardCommand{{"pbpaste"}}
+	default:
+		return []clipboardCommand{
+			{"wl-copy"},
+			{"xclip", "-selection", "clipboard"},
+			{"xsel", "--clipboard", "--input"},
+		}, []clipboardCommand{
+			{"wl-paste", "--no-newline"},
+			{"xclip", "-selection", "clipboard", "-o"},
+			{"xsel", "--clipboard", "--output"},
+		}
+	}
+}
+
+// writeClipboard copies text to the system clipboard using the first
+// clipboard program that works
+func writeClipboard(text string) error {
+	copiers, _ := clipboardCommands()
+	for _, c := range copiers {
+		cmd := exec.Command(c[0], c[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if cmd.Run() == nil {
+			return nil
+		}
+	}
+	return errors.New("no clipboard program found")
+}
+
+// readClipboard reads the system clipboard in the background
+func readClipboard() <-chan clipboardResult {
+	result := make(chan clipboardResult, 1)
+	go func() {
+		_, pasters := clipboardCommands()
+		for _, c := range pasters {
+			if out, err := exec.Command(c[0], c[1:]...).Output(); err == nil {
+				result <- clipboardResult{text: string(out)}
+				return
+			}
+		}
+		result <- clipboardResult{err: errors.New("no clipboard program found")}
+	}()
+	return result
+}
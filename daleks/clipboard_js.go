@@ -0,0 +1,54 @@
+package daleks
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+// browserClipboard returns the asynchronous clipboard API, if the browser
+// provides one
+func browserClipboard() (js.Value, error) {
+	clipboard := js.Global().Get("navigator").Get("clipboard")
+	if clipboard.IsUndefined() {
+		return clipboard, errors.New("the browser has no clipboard access")
+	}
+	return clipboard, nil
+}
+
+// writeClipboard copies text to the browser clipboard. The copy finishes
+// in the background.
+func writeClipboard(text string) error {
+	clipboard, err := browserClipboard()
+	if err != nil {
+		return err
+	}
+	clipboard.Call("writeText", text)
+	return nil
+}
+
+// readClipboard reads the browser clipboard in the background. The
+// browser may ask the player for permission first.
+func readClipboard() <-chan clipboardResult {
+	result := make(chan clipboardResult, 1)
+	clipboard, err := browserClipboard()
+	if err != nil {
+		result <- clipboardResult{err: err}
+		return result
+	}
+
+	var resolved, rejected js.Func
+	resolved = js.FuncOf(func(this js.Value, args []js.Value) any {
+		result <- clipboardResult{text: args[0].String()}
+		resolved.Release()
+		rejected.Release()
+		return nil
+	})
+	rejected = js.FuncOf(func(this js.Value, args []js.Value) any {
+		result <- clipboardResult{err: errors.New("clipboard access was refused")}
+		resolved.Release()
+		rejected.Release()
+		return nil
+	})
+	clipboard.Call("readText").Call("then", resolved, rejected)
+	return result
+}
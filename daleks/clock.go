@@ -0,0 +1,67 @@
+package daleks
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	simulationRate = 60                   // Simulation steps per second
+	simulationStep = 1.0 / simulationRate // Duration of one step in seconds
+	maxCatchUp     = 8                    // Most steps run to catch up on a backlog
+)
+
+// simClock turns Ebiten ticks into a fixed number of simulation steps, so
+// animation and input timing do not depend on the TPS setting or machine load
+type simClock struct {
+	accumulator float64 // Unsimulated time in seconds
+	steps       uint64  // Simulation steps run since the game started
+}
+
+// tickDuration returns how much real time a single Update call stands for
+func tickDuration() float64 {
+	tps := ebiten.TPS()
+	if tps == ebiten.SyncWithFPS {
+		// Update runs once per frame, so use the measured rate
+		if actual := ebiten.ActualTPS(); actual > 0 {
+			return 1.0 / actual
+		}
+		return simulationStep
+	}
+	return 1.0 / float64(tps)
+}
+
+// advance accounts for one Update call and returns how many simulation
+// steps should run for it
+func (c *simClock) advance(tick float64) int {
+	c.accumulator += tick
+
+	// A slow tick always runs every step it stands for, plus a few more
+	// to catch up on any backlog
+	limit := max(maxCatchUp, int(math.Ceil(tick*simulationRate)))
+	n := 0
+	for c.accumulator >= simulationStep && n < limit {
+		c.accumulator -= simulationStep
+		n++
+	}
+
+	// Drop whole steps we could not catch up on rather than spiralling,
+	// but keep the fraction of a step towards the next tick
+	if c.accumulator >= simulationStep {
+		c.accumulator = math.Mod(c.accumulator, simulationStep)
+	}
+
+	c.steps += uint64(n)
+	return n
+}
+
+// now returns the current simulation time in steps
+func (c *simClock) now() uint64 {
+	return c.steps
+}
+
+// stepsFor converts a duration in seconds to simulation steps
+func stepsFor(seconds float64) uint64 {
+	return uint64(seconds*simulationRate + 0.5)
+}
@@ -0,0 +1,48 @@
+package daleks
+
+import "testing"
+
+func TestClockRunsAtSimulationRate(t *testing.T) {
+	for _, tps := range []int{1, 5, 7, 30, 60, 144, 240} {
+		c := simClock{}
+		total := 0
+		for range 10 * tps {
+			total += c.advance(1.0 / float64(tps))
+		}
+		// Rounding may leave the last step for the next tick
+		if want := 10 * simulationRate; total < want-1 || total > want {
+			t.Errorf("%d TPS: %d steps in 10 seconds, want %d", tps, total, want)
+		}
+		if c.now() != uint64(total) {
+			t.Errorf("%d TPS: now() = %d, want %d", tps, c.now(), total)
+		}
+	}
+}
+
+func TestClockCatchUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		backlog float64 // Unsimulated time before the tick, in steps
+		tick    float64 // Tick length, in steps
+		want    int
+		left    float64 // Unsimulated time after the tick, in steps
+	}{
+		{"nothing due", 0, 0.5, 0, 0.5},
+		{"one step", 0, 1, 1, 0},
+		{"exactly the cap", 7.5, 1, maxCatchUp, 0.5},
+		{"over the cap", 20.25, 1, maxCatchUp, 0.25},
+		{"slow tick", 0.5, 12, 12, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := simClock{accumulator: tt.backlog * simulationStep}
+			if got := c.advance(tt.tick * simulationStep); got != tt.want {
+				t.Errorf("advance ran %d steps, want %d", got, tt.want)
+			}
+			if left := c.accumulator / simulationStep; left < tt.left-1e-6 || left > tt.left+1e-6 {
+				t.Errorf("%.3f steps left over, want %.3f", left, tt.left)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package daleks
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+// Config holds the startup options accepted by NewGame, usually parsed
+// from the command line
+type Config struct {
+	Seed       uint64  // Seed for the first game
+	HasSeed    bool    // Seed was given; start playing straight away
+	Level      int     // Level new games start on
+	RulesFile  string  // JSON rules file, empty for the default rules
+	Preset     string  // Difficulty preset selected on the menu
+	Mute       bool    // Run without audio
+	Fullscreen bool    // Start in fullscreen
+	Scale      float64 // Window scale factor
+	ShowGrid   bool    // Start with the grid overlay on
+	ReplayFile string  // Replay to play back at startup
+	RecordFile string  // Extra file the replay of each game is written to
+	BoardFile  string  // Text board to start playing at startup
+}
+
+// DefaultConfig returns the options used when no flags are given
+func DefaultConfig() Config {
+	return Config{
+		Level:  1,
+		Scale:  1,
+		Preset: "Normal",
+	}
+}
+
+// presets returns the rules new games can be played with and the index of
+// the one selected at startup. A rules file is offered after the built-in
+// presets and selected.
+func (c Config) presets() ([]engine.Preset, int, error) {
+	presets := engine.Presets()
+
+	if c.RulesFile != "" {
+		rules, err := engine.LoadRules(c.RulesFile)
+		if err != nil {
+			return nil, 0, fmt.Errorf("rules file %s: %w", c.RulesFile, err)
+		}
+		if rules.Width > maxBoardWidth || rules.Height > maxBoardHeight {
+			return nil, 0, fmt.Errorf("rules file %s: a %dx%d board does not fit the window (at most %dx%d)",
+				c.RulesFile, rules.Width, rules.Height, maxBoardWidth, maxBoardHeight)
+		}
+
+		custom := engine.Preset{Name: rules.Name, Description: "rules from " + filepath.Base(c.RulesFile), Rules: rules}
+		return append(presets, custom), len(presets), nil
+	}
+
+	for i, p := range presets {
+		if strings.EqualFold(p.Name, c.Preset) {
+			return presets, i, nil
+		}
+	}
+	_, err := engine.PresetRules(c.Preset)
+	return nil, 0, err
+}
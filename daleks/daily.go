@@ -0,0 +1,244 @@
+package daleks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+const dailyFileName = "daily.json"
+
+// dailyAttempt is the one scored attempt at a day's challenge
+type dailyAttempt struct {
+	Date      string       `json:"date"` // UTC date, YYYY-MM-DD
+	Seed      uint64       `json:"seed"`
+	Score     int          `json:"score"`
+	Level     int          `json:"level"`
+	Phase     engine.Phase `json:"phase"`
+	UsedUndo  bool         `json:"usedUndo,omitempty"`
+	Hints     int          `json:"hints,omitempty"`
+	Forfeited bool         `json:"forfeited,omitempty"` // Abandoned part way through
+	Replay    string       `json:"replay,omitempty"`    // File name in the replays directory
+}
+
+// String describes the attempt for the menu
+func (a dailyAttempt) String() string {
+	if a.Phase == engine.PhasePlaying && !a.Forfeited {
+		return fmt.Sprintf("Daily Challenge %s: attempt in progress", a.Date)
+	}
+
+	flag := ""
+	if a.UsedUndo {
+		flag = "*"
+	}
+	hints := ""
+	if a.Hints > 0 {
+		hints = fmt.Sprintf(", %d hints", a.Hints)
+	}
+	outcome := a.Phase.String()
+	if a.Forfeited {
+		outcome = "forfeited"
+	}
+	return fmt.Sprintf("Daily Challenge %s: %d%s points, level %d%s (%s)", a.Date, a.Score, flag, a.Level, hints, outcome)
+}
+
+// readDailyAttempts loads every recorded daily attempt. A missing file
+// means none have been played.
+func readDailyAttempts() ([]dailyAttempt, error) {
+	path, err := dataPath(dailyFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var attempts []dailyAttempt
+	if err := json.Unmarshal(data, &attempts); err != nil {
+		return nil, fmt.Errorf("daily challenge file is corrupted: %w", err)
+	}
+	return attempts, nil
+}
+
+// writeDailyAttempts stores the daily attempts in the data directory
+func writeDailyAttempts(attempts []dailyAttempt) error {
+	path, err := dataPath(dailyFileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(attempts, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// saveDailyAttempt adds or replaces the attempt for its date
+func saveDailyAttempt(attempt dailyAttempt) error {
+	attempts, err := readDailyAttempts()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range attempts {
+		if attempts[i].Date == attempt.Date {
+			attempts[i] = attempt
+			replaced = true
+		}
+	}
+	if !replaced {
+		attempts = append(attempts, attempt)
+	}
+	return writeDailyAttempts(attempts)
+}
+
+// forfeitStaleDailies marks attempts left in progress as forfeited, as
+// happens when the game is killed part way through one. An attempt whose
+// saved game can still be continued is left in progress.
+func forfeitStaleDailies() error {
+	attempts, err := readDailyAttempts()
+	if err != nil {
+		return err
+	}
+
+	resumable := ""
+	if saved, err := readSave(); err == nil {
+		resumable = saved.Daily
+	}
+
+	changed := false
+	for i := range attempts {
+		a := &attempts[i]
+		if a.Phase == engine.PhasePlaying && !a.Forfeited && a.Date != resumable {
+			a.Forfeited = true
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return writeDailyAttempts(attempts)
+}
+
+// findDailyAttempt returns the attempt recorded for a date
+func findDailyAttempt(date string) (dailyAttempt, bool) {
+	attempts, err := readDailyAttempts()
+	if err != nil {
+		log.Printf("could not read daily challenges: %v", err)
+		return dailyAttempt{}, false
+	}
+	for _, a := range attempts {
+		if a.Date == date {
+			return a, true
+		}
+	}
+	return dailyAttempt{}, false
+}
+
+// refreshDaily looks up today's challenge for the menu
+func (g *Game) refreshDaily() {
+	g.dailyDate = engine.DailyDate(time.Now())
+	g.dailyAttempt, g.dailyPlayed = findDailyAttempt(g.dailyDate)
+}
+
+// startDaily begins today's scored attempt, or plays back its replay once
+// it has been used
+func (g *Game) startDaily() {
+	g.refreshDaily()
+	if g.dailyPlayed {
+		g.watchDaily()
+		return
+	}
+
+	seed := engine.DailySeed(g.dailyDate)
+	attempt := dailyAttempt{Date: g.dailyDate, Seed: seed, Level: 1}
+
+	// The attempt counts as soon as it starts, so it cannot be retried
+	if err := saveDailyAttempt(attempt); err != nil {
+		g.menuMessage = "Could not start the daily challenge: " + err.Error()
+		return
+	}
+
+	g.resetBoard(engine.NewState(engine.DailyRules(), seed))
+	g.daily = g.dailyDate
+}
+
+// watchDaily plays back the replay of today's attempt
+func (g *Game) watchDaily() {
+	if g.dailyAttempt.Replay == "" {
+		g.menuMessage = g.dailyAttempt.String() + ". Come back tomorrow!"
+		return
+	}
+
+	path, err := dataPath(filepath.Join(replayDirName, g.dailyAttempt.Replay))
+	if err == nil {
+		var replay engine.Replay
+		if replay, err = readReplayFile(path); err == nil {
+			g.startPlayback(replay)
+			return
+		}
+	}
+	g.menuMessage = "Could not load replay: " + err.Error()
+}
+
+// abandonDaily forfeits a daily attempt the player walks away from, with
+// the score reached so far, so the day is not left in progress. An attempt
+// that ended while its last turn was still being animated is recorded as
+// it ended.
+func (g *Game) abandonDaily() {
+	if g.daily == "" || g.playback != nil {
+		return
+	}
+	g.finishDaily()
+	g.daily = ""
+}
+
+// finishDaily records the result and replay of a finished or forfeited
+// daily attempt
+func (g *Game) finishDaily() {
+	attempt := dailyAttempt{
+		Date:      g.daily,
+		Seed:      g.board.Seed,
+		Score:     g.board.Score,
+		Level:     g.board.Level,
+		Phase:     g.board.Phase,
+		UsedUndo:  g.usedUndo,
+		Hints:     g.hints,
+		Forfeited: g.board.Phase == engine.PhasePlaying,
+	}
+
+	name := "daily-" + g.daily + ".replay"
+	dir, err := dataPath(replayDirName)
+	if err == nil {
+		if err = os.MkdirAll(dir, 0o755); err == nil {
+			err = writeReplayFile(filepath.Join(dir, name), engine.NewReplay(g.board, g.history.played()))
+		}
+	}
+	if err != nil {
+		log.Printf("could not record daily replay: %v", err)
+	} else {
+		attempt.Replay = name
+	}
+
+	if err := saveDailyAttempt(attempt); err != nil {
+		log.Printf("could not record daily challenge: %v", err)
+	}
+}
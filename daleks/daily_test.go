@@ -0,0 +1,68 @@
+package daleks
+
+import (
+	"testing"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+func TestForfeitStaleDailies(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	attempts := []dailyAttempt{
+		{Date: "2026-01-01", Phase: engine.PhaseGameOver, Score: 120, Level: 3},
+		{Date: "2026-01-02", Phase: engine.PhasePlaying, Level: 1},
+		{Date: "2026-01-03", Phase: engine.PhasePlaying, Level: 2, Forfeited: true},
+		{Date: "2026-01-04", Phase: engine.PhasePlaying, Level: 1},
+	}
+	if err := writeDailyAttempts(attempts); err != nil {
+		t.Fatalf("writeDailyAttempts: %v", err)
+	}
+	saved := testGame(t)
+	saved.Daily = "2026-01-04"
+	if err := writeSave(saved); err != nil {
+		t.Fatalf("writeSave: %v", err)
+	}
+
+	if err := forfeitStaleDailies(); err != nil {
+		t.Fatalf("forfeitStaleDailies: %v", err)
+	}
+	got, err := readDailyAttempts()
+	if err != nil {
+		t.Fatalf("readDailyAttempts: %v", err)
+	}
+
+	want := map[string]bool{
+		"2026-01-01": false, // Finished
+		"2026-01-02": true,  // Left in progress
+		"2026-01-03": true,  // Already forfeited
+		"2026-01-04": false, // Can still be continued
+	}
+	for _, a := range got {
+		if a.Forfeited != want[a.Date] {
+			t.Errorf("%s: forfeited %v, want %v", a.Date, a.Forfeited, want[a.Date])
+		}
+	}
+	if len(got) != len(attempts) {
+		t.Errorf("%d attempts, want %d", len(got), len(attempts))
+	}
+}
+
+func TestDailyAttemptString(t *testing.T) {
+	tests := []struct {
+		attempt dailyAttempt
+		want    string
+	}{
+		{dailyAttempt{Date: "2026-01-01", Phase: engine.PhasePlaying}, "Daily Challenge 2026-01-01: attempt in progress"},
+		{dailyAttempt{Date: "2026-01-01", Phase: engine.PhaseGameOver, Score: 120, Level: 3}, "Daily Challenge 2026-01-01: 120 points, level 3 (caught)"},
+		{dailyAttempt{Date: "2026-01-01", Phase: engine.PhasePlaying, Score: 40, Level: 2, Forfeited: true}, "Daily Challenge 2026-01-01: 40 points, level 2 (forfeited)"},
+		{dailyAttempt{Date: "2026-01-01", Phase: engine.PhaseWin, Score: 900, Level: 10, UsedUndo: true, Hints: 2}, "Daily Challenge 2026-01-01: 900* points, level 10, 2 hints (won)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.attempt.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package daleks
+
+import "github.com/AaronSaikovski/godaleks/engine"
+
+// subscribeEffects starts the teleport and sonic screwdriver animations
+// when their events are played
+func (g *Game) subscribeEffects(bus *engine.Bus) {
+	bus.Subscribe(engine.EventPlayerTeleported, func(e engine.Event) {
+		g.teleportOldPos = e.From
+		g.teleportNewPos = e.Pos
+		g.teleportAnimation = true
+		g.teleportTimer = 0
+	})
+
+	bus.Subscribe(engine.EventScrewdriverFired, func(e engine.Event) {
+		// Start screwdriver animation if there are targets
+		if len(e.Targets) > 0 {
+			g.screwdriverTargets = e.Targets
+			g.screwdriverAnimation = true
+			g.screwdriverTimer = 0
+		}
+	})
+}
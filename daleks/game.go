@@ -3,10 +3,15 @@ package daleks
 import (
 	"fmt"
 	"image/color"
+	"log"
 	"math"
 	"math/rand"
-	"time"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/AaronSaikovski/godaleks/engine"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -17,14 +22,21 @@ import (
 const (
 	screenWidth  = 800
 	screenHeight = 600
-	gridWidth    = 50
-	gridHeight   = 35 // Reduced from 37 to 35 to ensure sprites stay in bounds
 	cellSize     = 16
+
+	// Largest board that fits below the HUD
+	maxBoardWidth  = screenWidth / cellSize
+	maxBoardHeight = 35
 )
 
-type Position struct {
-	X, Y int
-}
+// Seconds each turn of a wait-until-safe takes to animate
+const waitMoveDuration = 0.2
+
+// Minimum simulation steps between two accepted moves or clicks (100ms)
+var inputCooldown = stepsFor(0.1)
+
+// Position is a cell on the board
+type Position = engine.Position
 
 type FloatPosition struct {
 	X, Y float64
@@ -32,44 +44,88 @@ type FloatPosition struct {
 
 type GameState int
 
-var gameImages *DalekGameImages
-
-//r soundPlayer *SoundPlayer
-
 const (
 	StateMenu GameState = iota
 	StatePlaying
 	StateGameOver
 	StateWin
+	StatePackSelect
 )
 
 type Dalek struct {
-	GridPos   Position      // Current grid position
-	VisualPos FloatPosition // Interpolated visual position
-	TargetPos FloatPosition // Target visual position
-	IsMoving  bool          // Whether currently animating
-	MoveTimer float64       // Animation timer
+	Kind      engine.DalekKind // Normal, fast, armoured or the Emperor, for the sprite
+	Health    int              // Armour left, for the sprite
+	GridPos   Position         // Current grid position
+	VisualPos FloatPosition    // Interpolated visual position
+	TargetPos FloatPosition    // Target visual position
+	IsMoving  bool             // Whether currently animating
+	MoveTimer float64          // Animation timer
 }
 
 type Game struct {
 	state           GameState
-	player          Position
-	daleks          []Dalek // Changed from []Position to []Dalek
-	scraps          []Position
-	level           int
-	score           int
-	teleports       int
-	safeTeleports   int
-	screwdrivers    int
-	lastStands      int
+	board           engine.State   // Authoritative rules state
+	pending         []engine.Event // Events waiting to be animated
+	player          Position       // Displayed player position
+	daleks          []Dalek        // Displayed daleks
+	scraps          []Position     // Displayed scrap heaps
 	gameOverMessage string
-	lastMoveTime    time.Time
-
-	playerImage *ebiten.Image
-	dalekImage  *ebiten.Image
-	scrapImage  *ebiten.Image
+	lastMoveStep    uint64 // Simulation step of the last accepted move
+	seedInput       string // Seed typed on the menu, blank for a random one
+	canContinue     bool   // A saved game is available
+	casual          bool   // Casual mode allows undo and redo
+	usedUndo        bool   // Undo was used this game, flagged with the score
+	history         turnHistory
+	presets         []engine.Preset // Rules new games can be played with
+	preset          int             // Index of the preset chosen on the menu
+	startLevel      int             // Level new games start on
+	recordFile      string          // Extra file each replay is written to
+	playback        *playback       // Non-nil while watching a replay
+	bus             engine.Bus      // Delivers played events to subscribers
+	stats           Stats           // Statistics for the current game
+	logEvents       bool            // Log every event (toggled with the debug key)
+	canWatch        bool            // A recorded replay is available
+	menuMessage     string          // Problem to report on the menu
+	highScores      []highScore     // Local high score table, best first
+	highScoreRank   int             // Where the finished game placed, 0 if it did not
+	gameRecorded    bool            // The finished game's replay and result have been written
+	daily           string          // Date of the daily challenge being played, if any
+	dailyDate       string          // Today's daily challenge, shown on the menu
+	dailyAttempt    dailyAttempt    // Today's attempt, when dailyPlayed
+	dailyPlayed     bool            // Today's attempt has been used
+	puzzles         []engine.Puzzle // Built-in puzzles
+	puzzleProgress  map[string]puzzleResult
+	puzzle          int            // Index of the puzzle being played
+	inPuzzle        bool           // Playing a puzzle rather than a game
+	packs           []*engine.Pack // Built-in and user level packs
+	packsFailed     []string       // Level pack files that could not be loaded
+	packProgress    map[string]packProgress
+	packChoice      int                    // Index of the pack chosen on the level select screen
+	packLevel       int                    // Level chosen on the level select screen
+	intro           []string               // Lines of the pack level intro being shown
+	paste           <-chan clipboardResult // Clipboard being read for a board import
+	imported        bool                   // Playing a board imported from text
+	hints           int                    // Hints asked for this game, recorded with the score
+	hint            *engine.Hint           // Hint for the current board, if any
+	hintPending     <-chan hintResult      // Search running in the background
+	hintGen         int                    // Counts board changes, to spot stale hints
+
+	playerImage        *ebiten.Image
+	dalekImage         *ebiten.Image
+	fastDalekImage     *ebiten.Image
+	armouredImage      *ebiten.Image
+	damagedImage       *ebiten.Image
+	emperorImage       *ebiten.Image
+	playerMonoImage    *ebiten.Image // 1-bit sprites for monochrome rules
+	dalekMonoImage     *ebiten.Image
+	fastDalekMonoImage *ebiten.Image
+	armouredMonoImage  *ebiten.Image
+	damagedMonoImage   *ebiten.Image
+	emperorMonoImage   *ebiten.Image
+	scrapImage         *ebiten.Image
 	// Movement animation settings
 	moveAnimationDuration float64 // Duration for Dalek movement animation
+	moveDuration          float64 // Duration of the current Dalek movement
 	daleksMoving          bool    // Whether daleks are currently moving
 	// Teleportation animation
 	teleportAnimation bool
@@ -77,38 +133,34 @@ type Game struct {
 	teleportOldPos    Position
 	teleportNewPos    Position
 	// Sonic screwdriver animation
-	screwdriverAnimation  bool
-	screwdriverTimer      float64
-	screwdriverTargets    []Position
-	isLastStandActive     bool
-	showGrid              bool
-	gridToggleMessage     string
-	gridToggleMessageTime time.Time
+	screwdriverAnimation bool
+	screwdriverTimer     float64
+	screwdriverTargets   []Position
+	isLastStandActive    bool
+	isWaiting            bool // Playing out a wait-until-safe
+	showGrid             bool
+	showDanger           bool   // Danger overlay and what-if preview of hovered moves
+	notice               string // Temporary center-screen notification
+	noticeUntil          uint64 // Simulation step the notice disappears at
 	// Last Stand smooth movement
 	lastStandSpeed        float64 // Speed in cells per second during Last Stand
 	lastStandAcceleration float64 // Acceleration multiplier per second
 	lastStandMaxSpeed     float64 // Maximum speed cap
 	// Mouse support
-	lastClickTime time.Time
+	lastClickStep uint64
 	soundPlayer   *SoundPlayer
+	// Simulation clock
+	clock simClock
 }
 
-func init() {
-	gameImages = loadImages()
-
-	// ...existing code...
-	// soundPlayer, err := NewSoundPlayer()
-	// if err != nil {
-	// 	// Handle error appropriately for your game
-	// 	panic(err)
-	// }
-
-}
-
-// Loads images
+// Loads images, falling back to generated sprites for any that are missing
+// or corrupt
 func loadImages() *DalekGameImages {
 	gameImages := &DalekGameImages{}
-	gameImages.LoadImages()
+	if err := gameImages.LoadImages(); err != nil {
+		log.Printf("using placeholder sprites: %v", err)
+	}
+	gameImages.fillPlaceholders()
 	return gameImages
 }
 
@@ -138,9 +190,14 @@ func createScrapImage() *ebiten.Image {
 	centerX := size / 2
 	centerY := size / 2
 
+	// Pixel offset within the sprite
+	type pixel struct {
+		X, Y int
+	}
+
 	// Create Dalek debris - looks like scattered Dalek parts and metal fragments
 	// Main debris cluster in center
-	debrisPositions := []Position{
+	debrisPositions := []pixel{
 		// Central cluster
 		{centerX, centerY},
 		{centerX - 1, centerY},
@@ -181,7 +238,7 @@ func createScrapImage() *ebiten.Image {
 	}
 
 	// Add some additional random scattered bits to make it look more chaotic
-	additionalDebris := []Position{
+	additionalDebris := []pixel{
 		{centerX - 1, centerY + 3},
 		{centerX + 1, centerY - 3},
 		{centerX - 4, centerY + 1},
@@ -199,143 +256,348 @@ func createScrapImage() *ebiten.Image {
 	return img
 }
 
-func NewGame() *Game {
-	rand.Seed(time.Now().UnixNano())
+// NewGame creates the game from the startup config. Missing audio or sprites
+// are not fatal: the game falls back to silence and generated placeholder
+// sprites.
+func NewGame(cfg Config) (*Game, error) {
+	presets, preset, err := cfg.presets()
+	if err != nil {
+		return nil, err
+	}
 
-	soundPlayer, err := NewSoundPlayer()
+	highScores, err := readHighScores()
 	if err != nil {
-		// Handle error appropriately for your game
-		panic(err)
+		log.Printf("could not read high scores: %v", err)
 	}
 
-	g := &Game{
-		state:         StateMenu,
-		level:         1,
-		teleports:     10,
-		safeTeleports: 3,
-		screwdrivers:  2,
-		lastStands:    1,
-		lastMoveTime:  time.Now(),
+	soundPlayer := NewSilentPlayer()
+	if !cfg.Mute {
+		if soundPlayer, err = NewSoundPlayer(); err != nil {
+			log.Printf("audio unavailable, running silently: %v", err)
+			soundPlayer = NewSilentPlayer()
+		}
+	}
 
-		//playerImage:           createPlayerImage(),
-		//dalekImage:            createDalekImage(),
+	gameImages := loadImages()
 
-		playerImage: gameImages.Human,
-		dalekImage:  gameImages.Dalek,
+	g := &Game{
+		state: StateMenu,
+
+		playerImage:        gameImages.Human,
+		dalekImage:         gameImages.Dalek,
+		fastDalekImage:     gameImages.FastDalek,
+		armouredImage:      gameImages.ArmouredDalek,
+		damagedImage:       gameImages.DamagedDalek,
+		emperorImage:       gameImages.Emperor,
+		playerMonoImage:    gameImages.HumanMono,
+		dalekMonoImage:     gameImages.DalekMono,
+		fastDalekMonoImage: gameImages.FastDalekMono,
+		armouredMonoImage:  gameImages.ArmouredDalekMono,
+		damagedMonoImage:   gameImages.DamagedDalekMono,
+		emperorMonoImage:   gameImages.EmperorMono,
 
 		scrapImage:            createScrapImage(),
 		moveAnimationDuration: 0.6, // Duration for normal movement
 		daleksMoving:          false,
-		showGrid:              false, // Default OFF
+		showGrid:              cfg.ShowGrid, // Default OFF
 		// Last Stand smooth movement settings
 		lastStandSpeed:        2.0,  // Start speed in cells per second
 		lastStandAcceleration: 1.5,  // Speed multiplier per second
 		lastStandMaxSpeed:     20.0, // Maximum speed cap
-		lastClickTime:         time.Now(),
 		soundPlayer:           soundPlayer,
+		canContinue:           hasSave(),
+		canWatch:              hasLastReplay(),
+		presets:               presets,
+		preset:                preset,
+		highScores:            highScores,
+		startLevel:            cfg.Level,
+		recordFile:            cfg.RecordFile,
 	}
 
-	return g
+	if err := forfeitStaleDailies(); err != nil {
+		log.Printf("could not check daily challenges: %v", err)
+	}
+	g.refreshDaily()
+
+	g.puzzles = loadPuzzles()
+	if g.puzzleProgress, err = readPuzzleProgress(); err != nil {
+		log.Printf("could not read puzzle progress: %v", err)
+	}
+
+	g.packs, g.packsFailed = loadPacks()
+	if g.packProgress, err = readPackProgress(); err != nil {
+		log.Printf("could not read level pack progress: %v", err)
+	}
+
+	g.soundPlayer.Subscribe(&g.bus)
+	g.subscribeEffects(&g.bus)
+	g.stats.Subscribe(&g.bus)
+	g.subscribeEventLog(&g.bus)
+	g.subscribePacks(&g.bus)
+
+	// Launch straight into the requested scenario
+	switch {
+	case cfg.ReplayFile != "":
+		replay, err := readReplayFile(cfg.ReplayFile)
+		if err != nil {
+			return nil, fmt.Errorf("replay file %s: %w", cfg.ReplayFile, err)
+		}
+		g.startPlayback(replay)
+	case cfg.BoardFile != "":
+		data, err := os.ReadFile(cfg.BoardFile)
+		if err == nil {
+			err = g.loadBoard(string(data))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("board file %s: %w", cfg.BoardFile, err)
+		}
+	case cfg.HasSeed:
+		g.resetGame(cfg.Seed)
+	case cfg.Level > 1:
+		g.resetGame(randomSeed())
+	}
+
+	return g, nil
+}
+
+// randomSeed picks a short seed that is easy to read back and type in
+func randomSeed() uint64 {
+	return uint64(rand.Int63n(1000000000))
+}
+
+// menuSeed returns the seed typed on the menu, or a random one
+func (g *Game) menuSeed() uint64 {
+	if seed, err := strconv.ParseUint(g.seedInput, 10, 64); err == nil {
+		return seed
+	}
+	return randomSeed()
+}
+
+// updateSeedInput lets the player type a seed on the menu
+func (g *Game) updateSeedInput() {
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if r >= '0' && r <= '9' && len(g.seedInput) < 19 {
+			g.seedInput += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(g.seedInput) > 0 {
+		g.seedInput = g.seedInput[:len(g.seedInput)-1]
+	}
 }
 
 // resetGame resets the game to initial state and starts from level 1
-func (g *Game) resetGame() {
-	g.level = 1
-	g.score = 0
-	g.teleports = 10
-	g.safeTeleports = 3
-	g.screwdrivers = 2
-	g.lastStands = 1
-	g.teleportAnimation = false
-	g.teleportTimer = 0
-	g.screwdriverAnimation = false
-	g.screwdriverTimer = 0
-	g.screwdriverTargets = nil
-	g.daleksMoving = false
-	g.isLastStandActive = false
-	g.lastStandSpeed = 2.0
+func (g *Game) resetGame(seed uint64) {
+	g.resetBoard(engine.NewStateAt(g.presets[g.preset].Rules, seed, g.startLevel))
+}
+
+// resetBoard starts playing a freshly created board
+func (g *Game) resetBoard(board engine.State, events []engine.Event) {
+	g.recordGame()
+	g.clearAnimations()
+	g.gameOverMessage = ""
+	g.menuMessage = ""
+	g.playback = nil
+
+	g.board = board
+	g.pending = events
+	g.state = StatePlaying
+	g.usedUndo = false
+	g.gameRecorded = false
+	g.highScoreRank = 0
+	g.daily = ""
+	g.inPuzzle = false
+	g.imported = false
+	g.hints = 0
+	g.clearHint()
+	g.intro = nil
+	g.stats = Stats{}
+	g.history.reset(board, nil)
+	g.processEvents()
+}
+
+// returnToMenu clears the finished game and shows the menu
+func (g *Game) returnToMenu() {
+	g.recordGame()
+	g.clearAnimations()
+	// Clear any remaining game state
+	g.board = engine.State{}
 	g.daleks = nil
 	g.scraps = nil
 	g.gameOverMessage = ""
-	g.startLevel()
+	g.intro = nil
+	g.canContinue = hasSave()
+	g.canWatch = hasLastReplay()
+	g.refreshDaily()
+	g.state = StateMenu
 }
 
-func (g *Game) startLevel() {
-	// Clear the board and reset all states
-	g.scraps = nil
-	g.daleksMoving = false
-	g.isLastStandActive = false
-	g.lastStandSpeed = 2.0
-	g.teleportAnimation = false
-	g.teleportTimer = 0
-	g.screwdriverAnimation = false
-	g.screwdriverTimer = 0
-	g.screwdriverTargets = nil
-	g.lastStands = 1
+// continueGame restores the saved game
+func (g *Game) continueGame() {
+	saved, err := readSave()
+	if err != nil {
+		g.menuMessage = "Could not continue: " + err.Error()
+		g.canContinue = false
+		return
+	}
+
+	g.clearAnimations()
+	g.gameOverMessage = ""
+	g.menuMessage = ""
+	g.board = saved.Board
+	g.showGrid = saved.ShowGrid
+	g.casual = saved.Casual
+	g.usedUndo = saved.UsedUndo
+	g.daily = saved.Daily
+	g.puzzle, g.inPuzzle = g.findPuzzle(saved.Puzzle)
+	g.imported = saved.Imported
+	g.hints = saved.Hints
+	g.clearHint()
+	g.intro = nil
+	g.gameRecorded = false
+	g.highScoreRank = 0
+	g.stats = Stats{}
+	g.history.reset(saved.Board, saved.Actions)
+	g.syncBoard()
+	g.state = StatePlaying
+	g.soundPlayer.Play("gamestart")
+}
 
-	// Place player randomly
-	g.player = Position{
-		X: rand.Intn(gridWidth),
-		Y: rand.Intn(gridHeight),
+// saveOnQuit stores a game in progress so it can be continued later
+func (g *Game) saveOnQuit() {
+	if g.state != StatePlaying || g.playback != nil {
+		return
 	}
+	g.recordRequested()
 
-	// Place daleks (5 + level number)
-	dalekCount := 5 + g.level
-	g.daleks = make([]Dalek, 0, dalekCount)
+	// A daily attempt cannot be put aside for later, so quitting forfeits it
+	if g.daily != "" {
+		g.abandonDaily()
+		return
+	}
 
-	for len(g.daleks) < dalekCount {
-		pos := Position{
-			X: rand.Intn(gridWidth),
-			Y: rand.Intn(gridHeight),
-		}
+	saved := savedGame{
+		Board:    g.board,
+		ShowGrid: g.showGrid,
+		Casual:   g.casual,
+		UsedUndo: g.usedUndo,
+		Daily:    g.daily,
+		Puzzle:   g.puzzleName(),
+		Imported: g.imported,
+		Hints:    g.hints,
+		Actions:  g.history.played(),
+	}
+	if err := writeSave(saved); err != nil {
+		log.Printf("could not save game: %v", err)
+	}
+}
 
-		// Don't place dalek on player or too close
-		if g.distance(pos, g.player) > 3 && !g.positionOccupied(pos) {
-			dalek := Dalek{
-				GridPos:   pos,
-				VisualPos: FloatPosition{X: float64(pos.X), Y: float64(pos.Y)},
-				TargetPos: FloatPosition{X: float64(pos.X), Y: float64(pos.Y)},
-				IsMoving:  false,
-				MoveTimer: 0,
-			}
-			g.daleks = append(g.daleks, dalek)
-		}
+// finishGame discards the save once the game it belongs to has ended. Casual
+// games can still take back the final move from the game over screen, so
+// they are recorded when the player leaves it.
+func (g *Game) finishGame() {
+	if g.playback != nil {
+		return
 	}
+	removeSave()
+	g.canContinue = false
+	if g.inPuzzle {
+		g.showPuzzleResult()
+	}
+	if !g.undoAllowed() {
+		g.recordGame()
+	}
+}
 
-	g.state = StatePlaying
-	g.soundPlayer.Play("gamestart")
+// abandonGame records the replay of a game the player gave up on and
+// discards its save, as finishGame does for one that ended. Giving up on a
+// daily attempt forfeits it.
+func (g *Game) abandonGame() {
+	if g.playback != nil {
+		return
+	}
+	removeSave()
+	g.canContinue = false
+	switch {
+	case g.daily != "":
+		g.abandonDaily()
+	case !g.imported && g.board.Phase == engine.PhasePlaying:
+		g.recordReplay()
+	}
 }
 
-func (g *Game) distance(a, b Position) float64 {
-	dx := float64(a.X - b.X)
-	dy := float64(a.Y - b.Y)
-	return dx*dx + dy*dy // Using squared distance for efficiency
+// recordGame writes the replay and result of a finished game, once
+func (g *Game) recordGame() {
+	if g.playback != nil || g.imported || g.gameRecorded || g.board.Phase == engine.PhasePlaying {
+		return
+	}
+	g.gameRecorded = true
+
+	g.recordReplay()
+	switch {
+	case g.inPuzzle:
+		g.recordPuzzle()
+	case g.daily != "":
+		// Daily results are compared by date, not in the high score table
+		g.finishDaily()
+	default:
+		g.recordHighScore()
+	}
 }
 
-func (g *Game) positionOccupied(pos Position) bool {
-	for _, dalek := range g.daleks {
-		if dalek.GridPos == pos {
-			return true
-		}
+// winMessage congratulates the player on clearing the final level
+func (g *Game) winMessage() string {
+	if g.board.Pack != nil {
+		return fmt.Sprintf("Congratulations! You cleared every level of %s!", g.board.Pack.Name)
 	}
-	for _, scrap := range g.scraps {
-		if scrap == pos {
-			return true
-		}
+	return "Congratulations! You survived all levels!"
+}
+
+// clearAnimations stops every running animation and drops queued events
+func (g *Game) clearAnimations() {
+	g.pending = nil
+	g.teleportAnimation = false
+	g.teleportTimer = 0
+	g.screwdriverAnimation = false
+	g.screwdriverTimer = 0
+	g.screwdriverTargets = nil
+	g.daleksMoving = false
+	g.isLastStandActive = false
+	g.isWaiting = false
+	g.lastStandSpeed = 2.0
+}
+
+// syncBoard copies the rules state into the displayed board
+func (g *Game) syncBoard() {
+	g.player = g.board.Player
+	g.scraps = append([]Position(nil), g.board.Scraps...)
+	g.daleks = make([]Dalek, 0, len(g.board.Daleks))
+
+	for _, d := range g.board.Daleks {
+		pos := FloatPosition{X: float64(d.Pos.X), Y: float64(d.Pos.Y)}
+		g.daleks = append(g.daleks, Dalek{
+			Kind:      d.Kind,
+			Health:    d.Health,
+			GridPos:   d.Pos,
+			VisualPos: pos,
+			TargetPos: pos,
+		})
 	}
-	return false
+}
+
+// boardOffset returns the screen position of the top-left grid cell
+func (g *Game) boardOffset() (int, int) {
+	return (screenWidth - g.board.Rules.Width*cellSize) / 2, 50
 }
 
 // Convert screen coordinates to grid coordinates
 func (g *Game) screenToGrid(screenX, screenY int) (int, int, bool) {
-	offsetX := (screenWidth - gridWidth*cellSize) / 2
-	offsetY := 50
+	offsetX, offsetY := g.boardOffset()
 
 	gridX := (screenX - offsetX) / cellSize
 	gridY := (screenY - offsetY) / cellSize
 
 	// Check if within grid bounds
-	if gridX >= 0 && gridX < gridWidth && gridY >= 0 && gridY < gridHeight {
+	if g.board.InBounds(Position{X: gridX, Y: gridY}) {
 		return gridX, gridY, true
 	}
 	return 0, 0, false
@@ -344,10 +606,10 @@ func (g *Game) screenToGrid(screenX, screenY int) (int, int, bool) {
 // Handle mouse click for player movement
 func (g *Game) handleMouseClick(x, y int) {
 	// Prevent too rapid clicking
-	if time.Since(g.lastClickTime) < 100*time.Millisecond {
+	if g.clock.now() < g.lastClickStep+inputCooldown {
 		return
 	}
-	g.lastClickTime = time.Now()
+	g.lastClickStep = g.clock.now()
 
 	// Convert to grid coordinates
 	gridX, gridY, valid := g.screenToGrid(x, y)
@@ -359,206 +621,225 @@ func (g *Game) handleMouseClick(x, y int) {
 
 	// Check if clicking on current player position (stay in place)
 	if targetPos == g.player {
-		if !g.isLastStandActive {
-			g.moveDaleks()
-		}
+		g.act(engine.Action{Kind: engine.ActionWait})
 		return
 	}
 
-	// Calculate movement direction
-	dx := 0
-	dy := 0
+	// Move one step toward the clicked cell (adjacent cells including diagonal)
+	step := engine.StepToward(g.player, targetPos)
+	g.movePlayer(step.X-g.player.X, step.Y-g.player.Y)
+}
 
-	if targetPos.X > g.player.X {
-		dx = 1
-	} else if targetPos.X < g.player.X {
-		dx = -1
+func (g *Game) movePlayer(dx, dy int) {
+	// Prevent too rapid movement
+	if g.clock.now() < g.lastMoveStep+inputCooldown {
+		return
 	}
 
-	if targetPos.Y > g.player.Y {
-		dy = 1
-	} else if targetPos.Y < g.player.Y {
-		dy = -1
+	if g.act(engine.MoveAction(dx, dy)) {
+		g.lastMoveStep = g.clock.now()
 	}
+}
 
-	// Only allow one-step moves (adjacent cells including diagonal)
-	if abs(dx) <= 1 && abs(dy) <= 1 {
-		g.movePlayer(dx, dy)
-	}
+// busy reports whether the previous turn is still being animated
+func (g *Game) busy() bool {
+	return g.daleksMoving || len(g.pending) > 0
 }
 
-func (g *Game) movePlayer(dx, dy int) {
-	if g.state != StatePlaying || (g.daleksMoving && !g.isLastStandActive) {
-		return
+// act applies a player action to the rules state and queues its events
+func (g *Game) act(action engine.Action) bool {
+	if g.state != StatePlaying || g.busy() {
+		return false
 	}
 
-	// Prevent too rapid movement
-	if time.Since(g.lastMoveTime) < 100*time.Millisecond {
-		return
+	next, events := engine.Step(g.board, action)
+	if events == nil {
+		return false
 	}
-	g.lastMoveTime = time.Now()
 
-	newPos := Position{
-		X: g.player.X + dx,
-		Y: g.player.Y + dy,
+	if action.Kind == engine.ActionMove {
+		g.player = Position{X: g.player.X + action.DX, Y: g.player.Y + action.DY}
 	}
 
-	// Check bounds
-	if newPos.X < 0 || newPos.X >= gridWidth || newPos.Y < 0 || newPos.Y >= gridHeight {
-		return
-	}
+	g.board = next
+	g.pending = events
+	g.history.push(action, next)
+	g.clearHint()
+	g.processEvents()
+	return true
+}
 
-	// Check if position is occupied by scrap
-	for _, scrap := range g.scraps {
-		if scrap == newPos {
-			return
-		}
+// undo steps back one turn in casual mode
+func (g *Game) undo() {
+	if state, ok := g.history.undo(); ok {
+		g.usedUndo = true
+		g.restoreTurn(state)
 	}
+}
 
-	g.player = newPos
-
-	// In Last Stand mode, daleks move continuously, so no need to call moveDaleks
-	if !g.isLastStandActive {
-		g.moveDaleks()
+// redo steps forward one turn in casual mode
+func (g *Game) redo() {
+	if state, ok := g.history.redo(); ok {
+		g.restoreTurn(state)
 	}
 }
 
-func (g *Game) teleport(safe bool) {
+// restoreTurn puts a snapshot from the history back on the board
+func (g *Game) restoreTurn(state engine.State) {
+	g.clearAnimations()
+	g.gameOverMessage = ""
+	g.board = state
+	g.syncBoard()
+	g.clearHint()
+
+	switch state.Phase {
+	case engine.PhaseGameOver:
+		g.state = StateGameOver
+		g.gameOverMessage = "Game Over! You were caught by a Dalek!"
+		g.finishGame()
+	case engine.PhaseWin:
+		g.state = StateWin
+		g.gameOverMessage = g.winMessage()
+		g.finishGame()
+	default:
+		g.state = StatePlaying
+	}
+}
 
-	// ...existing code...
-	g.soundPlayer.Play("teleport")
+// undoAllowed reports whether the current game may undo and redo
+func (g *Game) undoAllowed() bool {
+	// Daily challenges are always scored, and recorded games are final
+	return g.casual && g.playback == nil && g.daily == "" && !g.gameRecorded
+}
 
-	if g.state != StatePlaying || (g.daleksMoving && !g.isLastStandActive) {
+// updateHistoryKeys handles undo and redo in casual mode
+func (g *Game) updateHistoryKeys() {
+	if !g.undoAllowed() {
 		return
 	}
-
-	if safe && g.safeTeleports <= 0 {
-		return
+	if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+		g.undo()
 	}
-	if !safe && g.teleports <= 0 {
-		return
+	if inpututil.IsKeyJustPressed(ebiten.KeyY) {
+		g.redo()
 	}
+}
 
-	// Store old position for animation
-	g.teleportOldPos = g.player
-
-	var newPos Position
-	maxAttempts := 100
-
-	if safe {
-		// Safe teleport - find position with no daleks nearby
-		for i := 0; i < maxAttempts; i++ {
-			newPos = Position{
-				X: rand.Intn(gridWidth),
-				Y: rand.Intn(gridHeight),
-			}
-
-			if !g.positionOccupied(newPos) && g.isSafePosition(newPos) {
-				break
-			}
-		}
-		g.safeTeleports--
-	} else {
-		// Regular teleport - just find an empty spot
-		for i := 0; i < maxAttempts; i++ {
-			newPos = Position{
-				X: rand.Intn(gridWidth),
-				Y: rand.Intn(gridHeight),
-			}
-
-			if !g.positionOccupied(newPos) {
-				break
-			}
-		}
-		g.teleports--
+// scoreFlag marks scores from games that used undo
+func (g *Game) scoreFlag() string {
+	var flags []string
+	if g.usedUndo {
+		flags = append(flags, "undo used")
 	}
-
-	// Start teleportation animation
-	g.teleportNewPos = newPos
-	g.teleportAnimation = true
-	g.teleportTimer = 0
-	g.player = newPos
-
-	if !g.isLastStandActive {
-		g.moveDaleks()
+	if g.hints > 0 {
+		flags = append(flags, fmt.Sprintf("%d hints", g.hints))
+	}
+	if len(flags) == 0 {
+		return ""
 	}
+	return " (" + strings.Join(flags, ", ") + ")"
 }
 
-func (g *Game) isSafePosition(pos Position) bool {
-	// Check if any dalek can reach this position in one move
-	for _, dalek := range g.daleks {
-		if g.distance(pos, dalek.GridPos) <= 2 { // Within one move
-			return false
-		}
+// processEvents plays queued events until one of them starts an animation
+func (g *Game) processEvents() {
+	for len(g.pending) > 0 && !g.daleksMoving {
+		event := g.pending[0]
+		g.pending = g.pending[1:]
+		g.applyEvent(event)
 	}
-	return true
 }
 
-func (g *Game) useScrewdriver() {
+// applyEvent updates the displayed board for an event and publishes it
+func (g *Game) applyEvent(event engine.Event) {
+	switch event.Kind {
+	case engine.EventDaleksMoved:
+		g.startDalekMovement(event.Moves)
 
-	if g.state != StatePlaying || g.screwdrivers <= 0 || (g.daleksMoving && !g.isLastStandActive) {
-		return
-	}
+	case engine.EventDalekHitScrap:
+		g.removeDaleksAt(event.Pos)
 
-	g.screwdrivers--
-
-	// Find all daleks adjacent to player (including diagonally)
-	daleksToDestroy := make([]int, 0)
-	g.screwdriverTargets = make([]Position, 0)
+	case engine.EventDaleksCollided:
+		g.removeDaleksAt(event.Pos)
+		if !event.Held {
+			g.scraps = append(g.scraps, event.Pos)
+		}
 
-	for i, dalek := range g.daleks {
-		dx := abs(dalek.GridPos.X - g.player.X)
-		dy := abs(dalek.GridPos.Y - g.player.Y)
+	case engine.EventEmperorDestroyed:
+		g.daleks = slices.DeleteFunc(g.daleks, func(d Dalek) bool {
+			return d.Kind == engine.DalekEmperor && d.GridPos == event.Pos
+		})
+		g.scraps = append(g.scraps, event.Targets...)
 
-		// Adjacent includes all 8 surrounding cells
-		if dx <= 1 && dy <= 1 && (dx != 0 || dy != 0) {
-			daleksToDestroy = append(daleksToDestroy, i)
-			g.screwdriverTargets = append(g.screwdriverTargets, dalek.GridPos)
+	case engine.EventEmperorSummoned:
+		for _, target := range event.Targets {
+			pos := FloatPosition{X: float64(target.X), Y: float64(target.Y)}
+			g.daleks = append(g.daleks, Dalek{GridPos: target, VisualPos: pos, TargetPos: pos})
 		}
-	}
 
-	// Start screwdriver animation if there are targets
-	if len(g.screwdriverTargets) > 0 {
-		g.screwdriverAnimation = true
-		g.soundPlayer.Play("screwdriver")
-		g.screwdriverTimer = 0
-	}
-
-	// Remove destroyed daleks and add scraps
-	newDaleks := make([]Dalek, 0, len(g.daleks))
-	for i, dalek := range g.daleks {
-		destroyed := false
-		for _, destroyIndex := range daleksToDestroy {
-			if i == destroyIndex {
-				destroyed = true
-				g.score += 5 // Bonus points for screwdriver kill
-				// Add debris pile at dalek's position
-				g.scraps = append(g.scraps, dalek.GridPos)
-				break
-			}
+	case engine.EventPlayerTeleported:
+		g.player = event.Pos
+
+	case engine.EventScrewdriverFired:
+		for _, target := range event.Targets {
+			g.removeDaleksAt(target)
+			g.scraps = append(g.scraps, target)
 		}
-		if !destroyed {
-			newDaleks = append(newDaleks, dalek)
+
+	case engine.EventPlayerCaught:
+		g.finishGame()
+		g.state = StateGameOver
+		g.gameOverMessage = "Game Over! You were caught by a Dalek!"
+		g.isLastStandActive = false // End Last Stand immediately
+
+	case engine.EventGameWon:
+		g.state = StateWin
+		g.gameOverMessage = g.winMessage()
+		g.finishGame()
+
+	case engine.EventOutOfTurns:
+		g.finishGame()
+		g.state = StateGameOver
+		g.gameOverMessage = "Out of turns! The Daleks are still standing."
+
+	case engine.EventLevelStarted:
+		g.syncBoard()
+		if slices.ContainsFunc(g.daleks, func(d Dalek) bool { return d.Kind == engine.DalekEmperor }) {
+			g.showNotice("The Emperor is here! Crash daleks into it to destroy it", 4)
 		}
-	}
+		g.checkMilestones(event.Level)
+		g.showIntro(event.Level)
+		g.isLastStandActive = false
+		g.lastStandSpeed = 2.0
+		g.state = StatePlaying
+
+	case engine.EventLastStandStarted:
+		g.isLastStandActive = true
+		g.lastStandSpeed = 2.0 // Reset speed to starting value
+
+	case engine.EventLastStandEnded:
+		g.isLastStandActive = false
 
-	g.daleks = newDaleks
+	case engine.EventWaitStarted:
+		g.isWaiting = true
 
-	// Move remaining daleks after screwdriver use (if not in Last Stand)
-	if !g.isLastStandActive {
-		g.moveDaleks()
+	case engine.EventWaitEnded:
+		g.isWaiting = false
 	}
+
+	// Sound, effects, statistics and logging react to the event independently
+	g.bus.Publish(event)
 }
 
-func (g *Game) lastStand() {
-	if g.state != StatePlaying || g.lastStands <= 0 || g.daleksMoving {
-		return
+// removeDaleksAt removes the daleks destroyed at pos. The Emperor only
+// leaves the board when it is destroyed.
+func (g *Game) removeDaleksAt(pos Position) {
+	remaining := g.daleks[:0]
+	for _, dalek := range g.daleks {
+		if dalek.GridPos != pos || dalek.Kind == engine.DalekEmperor {
+			remaining = append(remaining, dalek)
+		}
 	}
-
-	g.lastStands--
-	g.isLastStandActive = true
-	g.lastStandSpeed = 2.0 // Reset speed to starting value
-	g.daleksMoving = true  // Enable daleks movement for Last Stand
+	g.daleks = remaining
 }
 
 func abs(x int) int {
@@ -568,71 +849,40 @@ func abs(x int) int {
 	return x
 }
 
-func (g *Game) moveDaleks() {
-	// Start movement animation for all daleks
-	g.daleksMoving = true
-
-	for i := range g.daleks {
-		dalek := &g.daleks[i]
-
-		// Calculate new grid position
-		dx := 0
-		dy := 0
-
-		if dalek.GridPos.X < g.player.X {
-			dx = 1
-		} else if dalek.GridPos.X > g.player.X {
-			dx = -1
-		}
-
-		if dalek.GridPos.Y < g.player.Y {
-			dy = 1
-		} else if dalek.GridPos.Y > g.player.Y {
-			dy = -1
-		}
-
-		newGridPos := Position{
-			X: dalek.GridPos.X + dx,
-			Y: dalek.GridPos.Y + dy,
-		}
-
-		// Update dalek's positions for smooth animation
-		dalek.GridPos = newGridPos
-		dalek.TargetPos = FloatPosition{
-			X: float64(newGridPos.X),
-			Y: float64(newGridPos.Y),
-		}
-		dalek.IsMoving = true
-		dalek.MoveTimer = 0
+// startDalekMovement animates every dalek from its old cell to its new one
+func (g *Game) startDalekMovement(moves []engine.Move) {
+	g.daleks = make([]Dalek, 0, len(moves))
+	for _, move := range moves {
+		g.daleks = append(g.daleks, Dalek{
+			Kind:      move.Kind,
+			Health:    move.Health,
+			GridPos:   move.To,
+			VisualPos: FloatPosition{X: float64(move.From.X), Y: float64(move.From.Y)},
+			TargetPos: FloatPosition{X: float64(move.To.X), Y: float64(move.To.Y)},
+			IsMoving:  true,
+			MoveTimer: 0,
+		})
+	}
+
+	// Last Stand rounds play faster as the daleks pick up speed
+	g.moveDuration = g.moveAnimationDuration
+	if g.isLastStandActive {
+		g.moveDuration = 1.0 / g.lastStandSpeed
+	} else if g.isWaiting {
+		g.moveDuration = waitMoveDuration
 	}
+	g.daleksMoving = true
 }
 
 func (g *Game) updateDalekAnimations(deltaTime float64) {
-	// Ensure Last Stand is properly disabled if game is not in playing state
-	if g.state != StatePlaying {
-		g.isLastStandActive = false
-		g.daleksMoving = false
-		return
-	}
-
 	if g.isLastStandActive {
-		// Smooth continuous movement during Last Stand
-		g.updateLastStandMovement(deltaTime)
-	} else {
-		// Normal step-by-step movement
-		g.updateNormalMovement(deltaTime)
+		// Accelerate the movement speed
+		g.lastStandSpeed *= math.Pow(g.lastStandAcceleration, deltaTime)
+		if g.lastStandSpeed > g.lastStandMaxSpeed {
+			g.lastStandSpeed = g.lastStandMaxSpeed
+		}
 	}
-}
 
-// Add this function to check for collision with a threshold
-func (g *Game) checkCollisionWithThreshold(pos1, pos2 FloatPosition, threshold float64) bool {
-	dx := pos1.X - pos2.X
-	dy := pos1.Y - pos2.Y
-	distSquared := dx*dx + dy*dy
-	return distSquared < threshold*threshold
-}
-
-func (g *Game) updateNormalMovement(deltaTime float64) {
 	allFinished := true
 
 	for i := range g.daleks {
@@ -642,7 +892,7 @@ func (g *Game) updateNormalMovement(deltaTime float64) {
 			dalek.MoveTimer += deltaTime
 
 			// Calculate interpolation progress (0.0 to 1.0)
-			progress := dalek.MoveTimer / g.moveAnimationDuration
+			progress := dalek.MoveTimer / g.moveDuration
 			if progress >= 1.0 {
 				progress = 1.0
 				dalek.IsMoving = false
@@ -660,12 +910,6 @@ func (g *Game) updateNormalMovement(deltaTime float64) {
 			targetX := dalek.TargetPos.X
 			targetY := dalek.TargetPos.Y
 
-			// If this is the start of movement, set the start position
-			if dalek.MoveTimer <= deltaTime {
-				startX = dalek.VisualPos.X
-				startY = dalek.VisualPos.Y
-			}
-
 			// Interpolate position
 			dalek.VisualPos.X = startX + (targetX-startX)*easedProgress
 			dalek.VisualPos.Y = startY + (targetY-startY)*easedProgress
@@ -677,338 +921,196 @@ func (g *Game) updateNormalMovement(deltaTime float64) {
 		}
 	}
 
-	// Check if all daleks finished moving
+	// Play the rest of the turn once all daleks finished moving
 	if allFinished {
 		g.daleksMoving = false
-		g.checkCollisions()
+		g.processEvents()
 	}
 }
 
-func (g *Game) updateLastStandMovement(deltaTime float64) {
-	// Don't continue if game is over
-	if g.state != StatePlaying {
-		g.isLastStandActive = false
-		g.daleksMoving = false
-		return
+// simulate advances every animation by one fixed simulation step
+func (g *Game) simulate(deltaTime float64) {
+	// Update Dalek animations (handles both normal and Last Stand movement)
+	if g.daleksMoving {
+		g.updateDalekAnimations(deltaTime)
 	}
 
-	// Accelerate the movement speed
-	g.lastStandSpeed *= math.Pow(g.lastStandAcceleration, deltaTime)
-	if g.lastStandSpeed > g.lastStandMaxSpeed {
-		g.lastStandSpeed = g.lastStandMaxSpeed
+	// Update teleport animation
+	if g.teleportAnimation {
+		g.teleportTimer += deltaTime
+		if g.teleportTimer >= 0.5 {
+			g.teleportAnimation = false
+			g.teleportTimer = 0
+		}
 	}
 
-	anyMoving := false
-
-	// Convert player position to FloatPosition for consistent comparison
-	playerPos := FloatPosition{X: float64(g.player.X), Y: float64(g.player.Y)}
-	collisionThreshold := 0.5 // Adjust this value to fine-tune collision detection
-
-	// Check player-dalek collisions first
-	for _, dalek := range g.daleks {
-		if g.checkCollisionWithThreshold(playerPos, dalek.VisualPos, collisionThreshold) {
-			g.state = StateGameOver
-			g.soundPlayer.Play("gameover")
-			g.gameOverMessage = "Game Over! You were caught by a Dalek!"
-			g.isLastStandActive = false
-			g.daleksMoving = false
-			return
+	// Update screwdriver animation
+	if g.screwdriverAnimation {
+		g.screwdriverTimer += deltaTime
+		if g.screwdriverTimer >= 0.8 {
+			g.screwdriverAnimation = false
+			g.screwdriverTimer = 0
+			g.screwdriverTargets = nil
 		}
 	}
+}
 
-	// Update dalek positions
-	for i := range g.daleks {
-		dalek := &g.daleks[i]
-
-		dx := playerPos.X - dalek.VisualPos.X
-		dy := playerPos.Y - dalek.VisualPos.Y
-		dist := math.Sqrt(dx*dx + dy*dy)
-
-		if dist > 0.1 { // Still has distance to cover (increased threshold)
-			// Normalize direction
-			dx /= dist
-			dy /= dist
-
-			// Store old position for collision check
-			oldPos := dalek.VisualPos
-
-			// Move toward player at current speed
-			moveDistance := g.lastStandSpeed * deltaTime
-			dalek.VisualPos.X += dx * moveDistance
-			dalek.VisualPos.Y += dy * moveDistance
-
-			// Clamp to grid bounds
-			dalek.VisualPos.X = math.Max(0, math.Min(float64(gridWidth-1), dalek.VisualPos.X))
-			dalek.VisualPos.Y = math.Max(0, math.Min(float64(gridHeight-1), dalek.VisualPos.Y))
-
-			// Update grid position for collision detection
-			dalek.GridPos.X = int(math.Round(dalek.VisualPos.X))
-			dalek.GridPos.Y = int(math.Round(dalek.VisualPos.Y))
-
-			anyMoving = true
-
-			// Check for collisions with scraps
-			for _, scrap := range g.scraps {
-				scrapPos := FloatPosition{X: float64(scrap.X), Y: float64(scrap.Y)}
-				if g.checkCollisionWithThreshold(dalek.VisualPos, scrapPos, collisionThreshold) {
-					dalek.VisualPos = oldPos // Prevent moving through scraps
-					g.daleks = append(g.daleks[:i], g.daleks[i+1:]...)
-					g.score += 2
-					g.soundPlayer.Play("crash")
-					// Don't add duplicate scraps
-					if !g.positionOccupied(Position{X: int(oldPos.X), Y: int(oldPos.Y)}) {
-						g.scraps = append(g.scraps, Position{X: int(oldPos.X), Y: int(oldPos.Y)})
-					}
-					return
-				}
-			}
-
-			// Check for collisions with other daleks
-			for j := i + 1; j < len(g.daleks); j++ {
-				if g.checkCollisionWithThreshold(dalek.VisualPos, g.daleks[j].VisualPos, collisionThreshold) {
-					collisionPos := Position{
-						X: int((dalek.VisualPos.X + g.daleks[j].VisualPos.X) / 2),
-						Y: int((dalek.VisualPos.Y + g.daleks[j].VisualPos.Y) / 2),
-					}
-					g.daleks = append(g.daleks[:i], g.daleks[i+1:]...)
-					g.daleks = append(g.daleks[:j-1], g.daleks[j:]...)
-					g.score += 4 // 2 points per dalek
-					g.soundPlayer.Play("crash")
-					if !g.positionOccupied(collisionPos) {
-						g.scraps = append(g.scraps, collisionPos)
-					}
-					return
-				}
-			}
+func (g *Game) Update() error {
+	// Auto-save when the window is closed
+	if ebiten.IsWindowBeingClosed() {
+		g.recordGame()
+		g.saveOnQuit()
+		return ebiten.Termination
+	}
+
+	// Run the simulation in fixed steps, however long this tick was
+	for steps := g.clock.advance(tickDuration()); steps > 0; steps-- {
+		if g.playback == nil {
+			g.simulate(simulationStep)
+			continue
 		}
-	}
 
-	// Check collisions every few frames for better performance
-	// but still frequent enough for good responsiveness
-	if int(g.lastStandSpeed*deltaTime*60)%2 == 0 {
-		g.checkCollisions()
+		// Replays run several simulation steps per step when sped up
+		for i := 0; i < g.playback.speed && g.playback != nil; i++ {
+			g.simulate(simulationStep)
+			g.updatePlayback(simulationStep)
+		}
 	}
 
-	// If player died during collision check, end Last Stand immediately
-	if g.state != StatePlaying {
-		g.isLastStandActive = false
-		g.daleksMoving = false
-		return
+	// Show the hint once the background search finishes
+	if g.hintPending != nil {
+		g.updateHint()
 	}
 
-	// End Last Stand if no daleks are moving or they're all gone
-	if !anyMoving || len(g.daleks) == 0 {
-		g.isLastStandActive = false
-		g.daleksMoving = false
-		if g.state == StatePlaying && len(g.daleks) == 0 {
-			g.score += 50 // Bonus for surviving Last Stand
-		}
+	// Replays take no gameplay input
+	if g.playback != nil && g.state == StatePlaying {
+		g.updatePlaybackKeys()
+		return nil
 	}
-}
 
-func (g *Game) checkCollisions() {
-	// Early exit if game is already over
-	if g.state != StatePlaying {
-		return
+	// Pack level intros wait for a key or click
+	if g.state == StatePlaying && g.intro != nil {
+		g.updateIntro()
+		return nil
 	}
 
-	// Check player-dalek collision FIRST (most important check)
-	for _, dalek := range g.daleks {
-		if g.player == dalek.GridPos {
-			g.state = StateGameOver
-			g.soundPlayer.Play("gameover")
-			g.gameOverMessage = "Game Over! You were caught by a Dalek!"
-			g.isLastStandActive = false // End Last Stand immediately
-			g.daleksMoving = false
-			return
-		}
+	// Handle mouse input for player movement
+	if g.state == StatePlaying && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := ebiten.CursorPosition()
+		g.handleMouseClick(x, y)
 	}
 
-	// Check dalek-dalek and dalek-scrap collisions
-	newDaleks := make([]Dalek, 0, len(g.daleks))
-	collidedPositions := make(map[Position]bool)
-
-	// First pass: check for collisions with scraps
-	for _, dalek := range g.daleks {
-		collided := false
-
-		// Check collision with scraps
-		for _, scrap := range g.scraps {
-			if dalek.GridPos == scrap {
-				collided = true
-				g.soundPlayer.Play("crash")
-				g.score += 2
-				collidedPositions[dalek.GridPos] = true
-				break
-			}
-		}
-
-		if !collided {
-			newDaleks = append(newDaleks, dalek)
-		}
+	// Copy the board as text for bug reports and puzzles
+	if g.state != StateMenu && g.state != StatePackSelect && inpututil.IsKeyJustPressed(ebiten.KeyB) {
+		g.copyBoard()
 	}
 
-	// Second pass: check for dalek-dalek collisions
-	finalDaleks := make([]Dalek, 0, len(newDaleks))
-
-	for i, dalek := range newDaleks {
-		collided := false
-
-		// Check if this dalek collides with any other dalek
-		for j, other := range newDaleks {
-			if i != j && dalek.GridPos.X == other.GridPos.X && dalek.GridPos.Y == other.GridPos.Y {
-				collided = true
-				g.score += 2
-				collidedPositions[dalek.GridPos] = true
-				g.soundPlayer.Play("crash")
-				break
-			}
+	switch g.state {
+	case StateMenu:
+		g.updateSeedInput()
+		if g.paste != nil {
+			g.updatePaste()
+			return nil
 		}
-
-		if !collided {
-			finalDaleks = append(finalDaleks, dalek)
+		if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+			g.casual = !g.casual
 		}
-
-	}
-
-	// Add debris piles for all collided positions
-	for pos := range collidedPositions {
-		// Check if scrap already exists at this position
-		scrapExists := false
-		for _, scrap := range g.scraps {
-			if scrap == pos {
-				scrapExists = true
-				break
-			}
+		if inpututil.IsKeyJustPressed(ebiten.KeyP) || inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+			g.preset = (g.preset + 1) % len(g.presets)
 		}
-		if !scrapExists {
-			g.scraps = append(g.scraps, pos)
+		if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+			g.preset = (g.preset + len(g.presets) - 1) % len(g.presets)
 		}
-	}
-
-	g.daleks = finalDaleks
-
-	// Check player-dalek collision again after updating dalek positions
-	// (in case daleks moved onto player during collision resolution)
-	for _, dalek := range g.daleks {
-		if g.player == dalek.GridPos {
-			g.state = StateGameOver
-			g.soundPlayer.Play("gameover")
-			g.gameOverMessage = "Game Over! You were caught by a Dalek!"
-			g.isLastStandActive = false // End Last Stand immediately
-			g.daleksMoving = false
-			return
+		if g.canContinue && inpututil.IsKeyJustPressed(ebiten.KeyC) {
+			g.continueGame()
+			return nil
 		}
-	}
-
-	// Check if level is complete
-	if len(g.daleks) == 0 {
-		g.score += g.level * 10
-		g.level++
-		g.teleports += 2
-		// if g.level%3 == 0 { // Bonus screwdriver every 3 levels
-		// 	g.screwdrivers++
-		// }
-		g.screwdrivers += 2 // Increase screwdrivers by 2 every level
-		if g.level%5 == 0 { // Bonus last stand every 5 levels
-			g.lastStands++
+		if g.canWatch && inpututil.IsKeyJustPressed(ebiten.KeyV) {
+			g.watchLastReplay()
+			return nil
 		}
-		if g.level > 10 {
-			g.state = StateWin
-			g.gameOverMessage = "Congratulations! You survived all levels!"
-			g.soundPlayer.Play("gameover")
-		} else {
-			g.startLevel()
+		if inpututil.IsKeyJustPressed(ebiten.KeyD) {
+			g.startDaily()
+			return nil
 		}
-	}
-}
-
-func (g *Game) Update() error {
-	deltaTime := 1.0 / 60.0 // Assuming 60 FPS
-
-	// Handle mouse input for player movement
-	if g.state == StatePlaying && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		x, y := ebiten.CursorPosition()
-		g.handleMouseClick(x, y)
-	}
-
-	// Update Dalek animations (handles both normal and Last Stand movement)
-	if g.daleksMoving || g.isLastStandActive {
-		g.updateDalekAnimations(deltaTime)
-
-		// Double-check that game hasn't ended during Dalek movement
-		if g.state != StatePlaying {
-			g.isLastStandActive = false
-			g.daleksMoving = false
+		if len(g.puzzles) > 0 && inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+			g.startPuzzle(g.firstUnsolvedPuzzle())
+			return nil
 		}
-	}
-
-	// Update teleport animation
-	if g.teleportAnimation {
-		g.teleportTimer += deltaTime
-		if g.teleportTimer >= 0.5 {
-			g.teleportAnimation = false
-			g.teleportTimer = 0
+		if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+			g.openPackSelect()
+			return nil
 		}
-	}
-
-	// Update screwdriver animation
-	if g.screwdriverAnimation {
-		g.screwdriverTimer += deltaTime
-		if g.screwdriverTimer >= 0.8 {
-			g.screwdriverAnimation = false
-			g.screwdriverTimer = 0
-			g.screwdriverTargets = nil
+		if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+			g.importBoard()
+			return nil
 		}
-	}
-
-	switch g.state {
-	case StateMenu:
 		if inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-			g.startLevel()
+			g.resetGame(g.menuSeed())
 		}
 
+	case StatePackSelect:
+		g.updatePackSelect()
+
 	case StatePlaying:
 
-		// New Game - press N to start a new game
+		// New Game - press N to start a new game with the same rules,
+		// or the menu's rules after a daily challenge. Puzzles and pack
+		// levels start again.
+		if g.board.Pack != nil && inpututil.IsKeyJustPressed(ebiten.KeyN) {
+			g.abandonGame()
+			g.startPackLevel(g.board.Pack, g.board.Level)
+			return nil
+		}
+		if g.inPuzzle && (inpututil.IsKeyJustPressed(ebiten.KeyN) || inpututil.IsKeyJustPressed(ebiten.KeyBackspace)) {
+			g.abandonGame()
+			g.startPuzzle(g.puzzle)
+			return nil
+		}
 		if inpututil.IsKeyJustPressed(ebiten.KeyN) {
-			g.resetGame()
+			rules := g.board.Rules
+			if g.daily != "" {
+				rules = g.presets[g.preset].Rules
+			}
+			g.abandonGame()
+			g.resetBoard(engine.NewStateAt(rules, randomSeed(), g.startLevel))
 			return nil
 		}
 
+		g.updateHistoryKeys()
+
 		// Toggle grid
 		if inpututil.IsKeyJustPressed(ebiten.KeyG) {
 			g.showGrid = !g.showGrid
 
 			if g.showGrid {
-				g.gridToggleMessage = "Grid ON"
+				g.showNotice("Grid ON", 1.5)
 			} else {
-				g.gridToggleMessage = "Grid OFF"
+				g.showNotice("Grid OFF", 1.5)
 			}
-			g.gridToggleMessageTime = time.Now()
 		}
 
-		// Allow movement during Last Stand, but not during normal dalek movement
-		if !g.daleksMoving || g.isLastStandActive {
+		// Toggle the danger overlay
+		if inpututil.IsKeyJustPressed(ebiten.KeyO) {
+			g.showDanger = !g.showDanger
+
+			if g.showDanger {
+				g.showNotice("Danger overlay ON", 1.5)
+			} else {
+				g.showNotice("Danger overlay OFF", 1.5)
+			}
+		}
+
+		// Ask the solver for the best action
+		if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+			g.requestHint()
+		}
+
+		// Wait for the previous turn to finish animating
+		if !g.busy() {
 
 			// Movement and actions
 
-			// //UP
-			// if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
-			// 	g.movePlayer(0, -1)
-			// }
-			// //Down
-			// if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) || inpututil.IsKeyJustPressed(ebiten.KeyD) {
-			// 	g.movePlayer(0, 1)
-			// }
-			// //Left
-			// if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) {
-			// 	g.movePlayer(-1, 0)
-			// }
-			// //Right
-			// if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) {
-			// 	g.movePlayer(1, 0)
-			// }
 			//UP
 			if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
 				g.movePlayer(0, -1)
@@ -1042,58 +1144,64 @@ func (g *Game) Update() error {
 
 			// Stay in place
 			if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-				if !g.isLastStandActive {
-					g.moveDaleks()
-				}
+				g.act(engine.Action{Kind: engine.ActionWait})
 			}
 
 			// Teleport
 			if inpututil.IsKeyJustPressed(ebiten.KeyT) {
-				g.teleport(false)
+				g.act(engine.Action{Kind: engine.ActionTeleport})
 			}
 			if inpututil.IsKeyJustPressed(ebiten.KeyR) {
-				g.teleport(true)
+				g.act(engine.Action{Kind: engine.ActionSafeTeleport})
 			}
 
 			// Sonic screwdriver
 			if inpututil.IsKeyJustPressed(ebiten.KeyS) {
-				g.useScrewdriver()
+				g.act(engine.Action{Kind: engine.ActionScrewdriver})
 			}
 
 			// Last stand
 			if inpututil.IsKeyJustPressed(ebiten.KeyL) {
-				g.lastStand()
+				g.act(engine.Action{Kind: engine.ActionLastStand})
+			}
+
+			// Wait until a dalek is adjacent, as in robots
+			if inpututil.IsKeyJustPressed(ebiten.KeyW) {
+				g.act(engine.Action{Kind: engine.ActionWaitUntilSafe})
 			}
 		}
 
-		// Debug info - add this temporarily to see Last Stand status
+		// Toggle event logging, written by the event log subscriber
 		if inpututil.IsKeyJustPressed(ebiten.KeyD) {
-			fmt.Printf("Last Stand Debug - Active: %v, Moving: %v, Speed: %.2f, Daleks: %d, Last Stands Available: %d\n",
-				g.isLastStandActive, g.daleksMoving, g.lastStandSpeed, len(g.daleks), g.lastStands)
+			g.logEvents = !g.logEvents
+			log.Printf("event logging: %v", g.logEvents)
+
+			if g.logEvents {
+				g.showNotice("Event logging ON", 1.5)
+			} else {
+				g.showNotice("Event logging OFF", 1.5)
+			}
 		}
 
 	case StateGameOver, StateWin:
+		// Casual games can take back the final move
+		g.updateHistoryKeys()
+		if g.state == StatePlaying {
+			return nil
+		}
+
+		if g.inPuzzle {
+			g.updatePuzzleEndKeys()
+			return nil
+		}
+
 		if inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-			g.level = 1
-			g.score = 0
-			g.teleports = 10
-			g.safeTeleports = 3
-			g.screwdrivers = 2
-			g.lastStands = 1
-			g.teleportAnimation = false
-			g.teleportTimer = 0
-			g.screwdriverAnimation = false
-			g.screwdriverTimer = 0
-			g.screwdriverTargets = nil
-			g.daleksMoving = false
-			g.isLastStandActive = false
-			// Reset Last Stand speed settings
-			g.lastStandSpeed = 2.0
-			// Clear any remaining game state
-			g.daleks = nil
-			g.scraps = nil
-			g.gameOverMessage = ""
-			g.state = StateMenu
+			if g.board.Pack != nil && g.playback == nil {
+				g.openPackSelect()
+				return nil
+			}
+			g.playback = nil
+			g.returnToMenu()
 		}
 	}
 
@@ -1107,10 +1215,18 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	switch g.state {
 	case StateMenu:
 		g.drawMenu(screen)
+	case StatePackSelect:
+		g.drawPackSelect(screen)
 	case StatePlaying:
 		g.drawGame(screen)
+		g.drawDangerOverlay(screen)
+		g.drawHint(screen)
 		g.drawHUD(screen)
-		g.drawMouseIndicator(screen)
+		if g.intro != nil {
+			g.drawIntro(screen)
+		} else {
+			g.drawMouseIndicator(screen)
+		}
 	case StateGameOver, StateWin:
 		g.drawGame(screen)
 		g.drawHUD(screen)
@@ -1126,12 +1242,12 @@ func (g *Game) drawMenu(screen *ebiten.Image) {
 		"Use arrow keys or mouse to move",
 		"Q, E, Z, C for diagonal movement",
 		"N To start a new game",
-		"SPACE or . to wait",
+		"SPACE or . to wait, W to wait until a dalek is next to you",
 		"T to teleport randomly",
 		"R to teleport safely",
 		"S to use sonic screwdriver",
 		"L for Last Stand (all daleks rush you)",
-		"G to turn game grid On/Off",
+		"G to turn game grid On/Off, O for the danger overlay, H for a hint",
 		"",
 		"MOUSE: Click adjacent cell to move there",
 		"Click on player to wait in place",
@@ -1145,8 +1261,53 @@ func (g *Game) drawMenu(screen *ebiten.Image) {
 	}
 
 	for i, line := range instructions {
-		text.Draw(screen, line, basicfont.Face7x13, 50, 200+i*20, color.Black)
+		text.Draw(screen, line, basicfont.Face7x13, 50, 285+i*17, color.Black)
 	}
+
+	seed := "random"
+	if g.seedInput != "" {
+		seed = g.seedInput + "_"
+	}
+	seedLine := fmt.Sprintf("Seed: %s  (type digits to choose, BACKSPACE to clear)", seed)
+	text.Draw(screen, seedLine, basicfont.Face7x13, 50, 130, color.Black)
+
+	casual := "OFF"
+	if g.casual {
+		casual = "ON"
+	}
+	casualLine := fmt.Sprintf("Casual mode: %s  (U to toggle, allows undo/redo)", casual)
+	text.Draw(screen, casualLine, basicfont.Face7x13, 50, 148, color.Black)
+
+	preset := g.presets[g.preset]
+	presetLine := fmt.Sprintf("Difficulty: < %s >  %s  (P or LEFT/RIGHT to change)", preset.Name, preset.Description)
+	text.Draw(screen, presetLine, basicfont.Face7x13, 50, 166, color.Black)
+
+	best := "Best: none yet"
+	if entry, ok := bestScore(g.highScores, preset.Rules.Name); ok {
+		best = "Best: " + entry.String()
+	}
+	text.Draw(screen, best, basicfont.Face7x13, 50, 184, color.Black)
+
+	if g.menuMessage != "" {
+		text.Draw(screen, g.menuMessage, basicfont.Face7x13, 50, 202, color.Black)
+	} else if g.canContinue {
+		text.Draw(screen, "Press C to continue your saved game", basicfont.Face7x13, 50, 202, color.Black)
+	}
+	if g.canWatch {
+		text.Draw(screen, "Press V to watch your last game", basicfont.Face7x13, 50, 217, color.Black)
+	}
+
+	daily := fmt.Sprintf("Press D for the Daily Challenge (%s, one scored attempt)", g.dailyDate)
+	if g.dailyPlayed {
+		daily = g.dailyAttempt.String() + "  (D to watch)"
+	}
+	text.Draw(screen, daily, basicfont.Face7x13, 50, 232, color.Black)
+
+	if len(g.puzzles) > 0 {
+		text.Draw(screen, g.puzzleSummary(), basicfont.Face7x13, 50, 247, color.Black)
+	}
+	text.Draw(screen, g.packSummary(), basicfont.Face7x13, 50, 262, color.Black)
+	text.Draw(screen, "Press I to import a board (B copies one in game)", basicfont.Face7x13, 400, 262, color.Black)
 }
 
 func (g *Game) drawMouseIndicator(screen *ebiten.Image) {
@@ -1171,8 +1332,7 @@ func (g *Game) drawMouseIndicator(screen *ebiten.Image) {
 
 	// Only show indicator for valid moves or current position
 	if dx <= 1 && dy <= 1 {
-		offsetX := (screenWidth - gridWidth*cellSize) / 2
-		offsetY := 50
+		offsetX, offsetY := g.boardOffset()
 
 		x := float64(offsetX + gridX*cellSize)
 		y := float64(offsetY + gridY*cellSize)
@@ -1198,8 +1358,11 @@ func (g *Game) drawMouseIndicator(screen *ebiten.Image) {
 			}
 		}
 
-		// Draw semi-transparent overlay on the cell
-		ebitenutil.DrawRect(screen, x, y, cellSize, cellSize, indicatorColor)
+		// Draw semi-transparent overlay on the cell, or only the border
+		// in black and white
+		if !g.board.Rules.Monochrome {
+			ebitenutil.DrawRect(screen, x, y, cellSize, cellSize, indicatorColor)
+		}
 
 		// Draw border
 		ebitenutil.DrawRect(screen, x, y, cellSize, 1, color.Black)
@@ -1313,9 +1476,46 @@ func (g *Game) drawScrewdriverEffect(screen *ebiten.Image, pos Position, progres
 	}
 }
 
+// sprites returns the player and dalek sprites for the current rules
+func (g *Game) sprites() (*ebiten.Image, *ebiten.Image) {
+	if g.board.Rules.Monochrome {
+		return g.playerMonoImage, g.dalekMonoImage
+	}
+	return g.playerImage, g.dalekImage
+}
+
+// dalekSprite returns the sprite for a kind of dalek with the given armour
+// left
+func (g *Game) dalekSprite(kind engine.DalekKind, health int) *ebiten.Image {
+	_, dalekImage := g.sprites()
+	mono := g.board.Rules.Monochrome
+	switch {
+	case kind == engine.DalekFast && mono:
+		return g.fastDalekMonoImage
+	case kind == engine.DalekFast:
+		return g.fastDalekImage
+	case kind == engine.DalekArmoured && health > 0 && mono:
+		return g.armouredMonoImage
+	case kind == engine.DalekArmoured && health > 0:
+		return g.armouredImage
+	case kind == engine.DalekArmoured && mono:
+		return g.damagedMonoImage
+	case kind == engine.DalekArmoured:
+		return g.damagedImage
+	case kind == engine.DalekEmperor && mono:
+		return g.emperorMonoImage
+	case kind == engine.DalekEmperor:
+		return g.emperorImage
+	}
+	return dalekImage
+}
+
 func (g *Game) drawGame(screen *ebiten.Image) {
-	offsetX := (screenWidth - gridWidth*cellSize) / 2
-	offsetY := 50
+	playerImage, _ := g.sprites()
+
+	offsetX, offsetY := g.boardOffset()
+	gridWidth := g.board.Rules.Width
+	gridHeight := g.board.Rules.Height
 
 	// Draw grid only if enabled
 	if g.showGrid {
@@ -1344,12 +1544,16 @@ func (g *Game) drawGame(screen *ebiten.Image) {
 
 	// Draw daleks using smooth interpolated positions (centered)
 	for _, dalek := range g.daleks {
-		// Use visual position for smooth movement, but calculate centered position
-		cellCenterX := float64(offsetX) + dalek.VisualPos.X*float64(cellSize) + float64(cellSize)/2
-		cellCenterY := float64(offsetY) + dalek.VisualPos.Y*float64(cellSize) + float64(cellSize)/2
+		dalekImage := g.dalekSprite(dalek.Kind, dalek.Health)
+
+		// Use visual position for smooth movement, but calculate centered
+		// position over every cell the dalek covers
+		span := float64(dalek.Kind.Size() * cellSize)
+		cellCenterX := float64(offsetX) + dalek.VisualPos.X*float64(cellSize) + span/2
+		cellCenterY := float64(offsetY) + dalek.VisualPos.Y*float64(cellSize) + span/2
 
 		// Get sprite dimensions and center it
-		spriteBounds := g.dalekImage.Bounds()
+		spriteBounds := dalekImage.Bounds()
 		spriteWidth := spriteBounds.Dx()
 		spriteHeight := spriteBounds.Dy()
 
@@ -1358,7 +1562,7 @@ func (g *Game) drawGame(screen *ebiten.Image) {
 
 		op := &ebiten.DrawImageOptions{}
 		op.GeoM.Translate(x, y)
-		screen.DrawImage(g.dalekImage, op)
+		screen.DrawImage(dalekImage, op)
 	}
 
 	// Draw player with teleportation effects (centered)
@@ -1377,7 +1581,7 @@ func (g *Game) drawGame(screen *ebiten.Image) {
 		}
 
 		// Draw player with fade effect (centered)
-		x, y := getCenteredSpritePosition(g.player.X, g.player.Y, offsetX, offsetY, g.playerImage)
+		x, y := getCenteredSpritePosition(g.player.X, g.player.Y, offsetX, offsetY, playerImage)
 
 		op := &ebiten.DrawImageOptions{}
 		op.GeoM.Translate(x, y)
@@ -1390,14 +1594,14 @@ func (g *Game) drawGame(screen *ebiten.Image) {
 			op.ColorM.Scale(1, 1, 1, 0) // Invisible during first part
 		}
 
-		screen.DrawImage(g.playerImage, op)
+		screen.DrawImage(playerImage, op)
 	} else {
 		// Normal player drawing (centered)
-		x, y := getCenteredSpritePosition(g.player.X, g.player.Y, offsetX, offsetY, g.playerImage)
+		x, y := getCenteredSpritePosition(g.player.X, g.player.Y, offsetX, offsetY, playerImage)
 
 		op := &ebiten.DrawImageOptions{}
 		op.GeoM.Translate(x, y)
-		screen.DrawImage(g.playerImage, op)
+		screen.DrawImage(playerImage, op)
 	}
 
 	// Draw screwdriver effects
@@ -1411,26 +1615,87 @@ func (g *Game) drawGame(screen *ebiten.Image) {
 }
 
 func (g *Game) drawGameOver(screen *ebiten.Image) {
-	// Semi-transparent overlay
-	ebitenutil.DrawRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{0, 0, 0, 128})
+	// Semi-transparent overlay, or a solid panel in black and white
+	if g.board.Rules.Monochrome {
+		ebitenutil.DrawRect(screen, 0, screenHeight/2-50, screenWidth, 150, color.Black)
+	} else {
+		ebitenutil.DrawRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{0, 0, 0, 128})
+	}
 
 	// Game over message
 	text.Draw(screen, g.gameOverMessage, basicfont.Face7x13,
 		screenWidth/2-len(g.gameOverMessage)*3, screenHeight/2-20, color.White)
 
-	finalScore := fmt.Sprintf("Final Score: %d", g.score)
+	finalScore := fmt.Sprintf("Final Score: %d%s", g.board.Score, g.scoreFlag())
 	text.Draw(screen, finalScore, basicfont.Face7x13,
 		screenWidth/2-len(finalScore)*3, screenHeight/2+10, color.White)
 
+	seed := fmt.Sprintf("Seed: %d", g.board.Seed)
+	if g.inPuzzle {
+		p := g.puzzles[g.puzzle]
+		seed = fmt.Sprintf("%s  Turns: %d  Par: %d", p.Name, g.board.Turns, p.Par)
+	}
+	text.Draw(screen, seed, basicfont.Face7x13,
+		screenWidth/2-len(seed)*3, screenHeight/2+25, color.White)
+
+	stats := g.stats.Summary()
+	text.Draw(screen, stats, basicfont.Face7x13,
+		screenWidth/2-len(stats)*3, screenHeight/2+60, color.White)
+
+	if g.highScoreRank > 0 && g.playback == nil {
+		rank := fmt.Sprintf("High score #%d on %s!", g.highScoreRank, g.board.Rules.Name)
+		text.Draw(screen, rank, basicfont.Face7x13,
+			screenWidth/2-len(rank)*3, screenHeight/2+80, color.White)
+	}
+	if g.undoAllowed() {
+		undo := "U to take back the last turn, the score is recorded when you move on"
+		text.Draw(screen, undo, basicfont.Face7x13,
+			screenWidth/2-len(undo)*3, screenHeight/2+80, color.White)
+	}
+	if g.daily != "" && g.playback == nil {
+		daily := fmt.Sprintf("Daily Challenge %s recorded. Come back tomorrow!", g.daily)
+		text.Draw(screen, daily, basicfont.Face7x13,
+			screenWidth/2-len(daily)*3, screenHeight/2+80, color.White)
+	}
+
 	restart := "Press SPACE or click to restart"
+	if g.inPuzzle {
+		restart = "SPACE: next puzzle  BACKSPACE: retry  ESC: menu"
+		if g.board.Phase != engine.PhaseWin {
+			restart = "SPACE or BACKSPACE: retry  ESC: menu"
+		}
+	} else if g.board.Pack != nil && g.playback == nil {
+		restart = "Press SPACE or click for level select"
+	}
 	text.Draw(screen, restart, basicfont.Face7x13,
 		screenWidth/2-len(restart)*3, screenHeight/2+40, color.White)
 }
 
 func (g *Game) drawHUD(screen *ebiten.Image) {
 	// Status information
-	status := fmt.Sprintf("Level: %d  Score: %d  Teleports: %d  Safe: %d  Screwdrivers: %d  Last Stands: %d  Daleks: %d",
-		g.level, g.score, g.teleports, g.safeTeleports, g.screwdrivers, g.lastStands, len(g.daleks))
+	teleports := strconv.Itoa(g.board.Teleports)
+	if g.board.Rules.UnlimitedTeleports {
+		teleports = "unlimited"
+	}
+	lastStands := strconv.Itoa(g.board.LastStands)
+	if g.board.Rules.UnlimitedLastStands {
+		lastStands = "unlimited"
+	}
+	status := fmt.Sprintf("Level: %d  Score: %d  Teleports: %s  Safe: %d  Screwdrivers: %d  Last Stands: %s  Daleks: %d",
+		g.board.Level, g.board.Score, teleports, g.board.SafeTeleports, g.board.Screwdrivers, lastStands, len(g.board.Daleks))
+	kinds := make(map[engine.DalekKind]int)
+	for _, dalek := range g.board.Daleks {
+		kinds[dalek.Kind]++
+	}
+	var special []string
+	for _, kind := range []engine.DalekKind{engine.DalekFast, engine.DalekArmoured} {
+		if kinds[kind] > 0 {
+			special = append(special, fmt.Sprintf("%d %s", kinds[kind], kind))
+		}
+	}
+	if len(special) > 0 {
+		status += " (" + strings.Join(special, ", ") + ")"
+	}
 	text.Draw(screen, status, basicfont.Face7x13, 10, 20, color.Black)
 
 	// Grid indicator
@@ -1440,21 +1705,87 @@ func (g *Game) drawHUD(screen *ebiten.Image) {
 	}
 	text.Draw(screen, gridStatus, basicfont.Face7x13, 10, 40, color.Black)
 
+	// Casual mode indicator
+	if g.undoAllowed() {
+		casual := "Casual: U undo, Y redo" + g.scoreFlag()
+		text.Draw(screen, casual, basicfont.Face7x13, 100, 40, color.Black)
+	}
+
+	// Puzzle or pack progress, or depth goals for games without a final level
+	if g.inPuzzle {
+		text.Draw(screen, g.puzzleStatus(), basicfont.Face7x13, 345, 40, color.Black)
+	} else if g.board.Pack != nil {
+		text.Draw(screen, g.packStatus(), basicfont.Face7x13, 345, 40, color.Black)
+	} else if g.board.Rules.MaxLevel == 0 {
+		depth := fmt.Sprintf("Best level: %d  Next milestone: %d",
+			bestLevel(g.highScores, g.board.Rules.Name), (g.board.Level/milestoneEvery+1)*milestoneEvery)
+		text.Draw(screen, depth, basicfont.Face7x13, 345, 40, color.Black)
+	}
+
+	// Preset and seed indicator
+	seed := fmt.Sprintf("Seed: %d", g.board.Seed)
+	if brain := g.board.Rules.BrainFor(g.board.Level); brain != engine.BrainGreedy {
+		seed = brain + " daleks  " + seed
+	}
+	if g.board.Rules.Name != "" {
+		seed = g.board.Rules.Name + "  " + seed
+	}
+	text.Draw(screen, seed, basicfont.Face7x13, screenWidth-10-len(seed)*7, 40, color.Black)
+
+	// Replay indicator
+	if g.playback != nil {
+		text.Draw(screen, g.playbackStatus(), basicfont.Face7x13, 10, screenHeight-10, color.Black)
+	}
+
+	g.drawBossBar(screen)
+
 	// Last Stand indicator
 	if g.isLastStandActive {
 		lastStandMsg := fmt.Sprintf("LAST STAND ACTIVE! Speed: %.1f", g.lastStandSpeed)
 		text.Draw(screen, lastStandMsg, basicfont.Face7x13, 10, screenHeight-30, color.Black)
 	}
 
-	// Temporary center-screen notification for grid toggle
-	if g.gridToggleMessage != "" && time.Since(g.gridToggleMessageTime) < 1500*time.Millisecond {
-		msg := g.gridToggleMessage
+	// Temporary center-screen notification
+	if g.notice != "" && g.clock.now() < g.noticeUntil {
+		msg := g.notice
 		x := screenWidth/2 - len(msg)*3
 		y := 60
 		text.Draw(screen, msg, basicfont.Face7x13, x, y, color.Black)
 	}
 }
 
+// drawBossBar shows how many more crashes the Emperor can take while it is
+// on the board
+func (g *Game) drawBossBar(screen *ebiten.Image) {
+	i := slices.IndexFunc(g.board.Daleks, func(d engine.Dalek) bool { return d.Kind == engine.DalekEmperor })
+	if i < 0 {
+		return
+	}
+
+	const barWidth, barHeight = 150, 10
+	x := float64(screenWidth - 10 - barWidth)
+	y := float64(screenHeight - 22)
+	label := fmt.Sprintf("Emperor %d", g.board.Daleks[i].Health)
+	text.Draw(screen, label, basicfont.Face7x13, int(x)-8-len(label)*7, int(y)+barHeight, color.Black)
+
+	fill := color.Color(color.RGBA{200, 0, 0, 255})
+	if g.board.Rules.Monochrome {
+		fill = color.Black
+	}
+	health := min(float64(g.board.Daleks[i].Health)/float64(max(g.board.Rules.EmperorHealth, 1)), 1)
+	ebitenutil.DrawRect(screen, x, y, barWidth*health, barHeight, fill)
+	ebitenutil.DrawRect(screen, x, y, barWidth, 1, color.Black)
+	ebitenutil.DrawRect(screen, x, y+barHeight-1, barWidth, 1, color.Black)
+	ebitenutil.DrawRect(screen, x, y, 1, barHeight, color.Black)
+	ebitenutil.DrawRect(screen, x+barWidth-1, y, 1, barHeight, color.Black)
+}
+
+// showNotice shows a message in the middle of the screen for a while
+func (g *Game) showNotice(msg string, seconds float64) {
+	g.notice = msg
+	g.noticeUntil = g.clock.now() + stepsFor(seconds)
+}
+
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return screenWidth, screenHeight
 }
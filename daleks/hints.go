@@ -0,0 +1,125 @@
+package daleks
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// hintResult is a finished search, for the board it was asked about
+type hintResult struct {
+	hint engine.Hint
+	gen  int // hintGen when the hint was requested
+}
+
+// hintsAllowed reports whether the current game may ask for hints
+func (g *Game) hintsAllowed() bool {
+	// Daily challenges are compared without help
+	return g.playback == nil && g.daily == ""
+}
+
+// requestHint starts searching for the best action in the background, so
+// the search never holds up a frame. Every request counts against the score.
+func (g *Game) requestHint() {
+	if !g.hintsAllowed() || g.hintPending != nil || g.busy() {
+		return
+	}
+
+	g.hints++
+	board, gen := g.board.Clone(), g.hintGen
+	result := make(chan hintResult, 1)
+	go func() {
+		result <- hintResult{hint: searchHint(board), gen: gen}
+	}()
+	g.hintPending = result
+	g.showNotice("Thinking...", 10)
+}
+
+// updateHint shows the hint once the search has finished, unless the
+// board has changed since it was asked for
+func (g *Game) updateHint() {
+	select {
+	case result := <-g.hintPending:
+		g.hintPending = nil
+		if result.gen != g.hintGen {
+			g.notice = ""
+			return
+		}
+		g.hint = &result.hint
+		g.showNotice(describeHint(result.hint), 4)
+	default:
+	}
+}
+
+// clearHint forgets the hint shown for the previous board
+func (g *Game) clearHint() {
+	g.hint = nil
+	g.hintGen++
+}
+
+// describeHint puts a hint into words
+func describeHint(h engine.Hint) string {
+	if h.ForcedLoss {
+		return fmt.Sprintf("Forced loss: nothing survives the next %d turns", h.Depth)
+	}
+
+	var action string
+	switch h.Action.Kind {
+	case engine.ActionMove:
+		action = "move " + directionName(h.Action.DX, h.Action.DY)
+	case engine.ActionWait:
+		action = "wait"
+	case engine.ActionScrewdriver:
+		action = "use the sonic screwdriver"
+	case engine.ActionTeleport:
+		action = "teleport"
+	case engine.ActionSafeTeleport:
+		action = "safe teleport"
+	}
+
+	if h.Survival < 1 {
+		return fmt.Sprintf("Hint: %s (%.0f%% chance to survive)", action, h.Survival*100)
+	}
+	return "Hint: " + action
+}
+
+// directionName names a move direction for hints
+func directionName(dx, dy int) string {
+	vertical := map[int]string{-1: "up", 1: "down"}[dy]
+	horizontal := map[int]string{-1: "left", 1: "right"}[dx]
+	if vertical != "" && horizontal != "" {
+		return vertical + "-" + horizontal
+	}
+	return vertical + horizontal
+}
+
+// drawHint outlines the cell the hinted action plays on
+func (g *Game) drawHint(screen *ebiten.Image) {
+	if g.hint == nil || g.hint.ForcedLoss {
+		return
+	}
+
+	cell := g.board.Player
+	switch g.hint.Action.Kind {
+	case engine.ActionMove:
+		cell.X += g.hint.Action.DX
+		cell.Y += g.hint.Action.DY
+	case engine.ActionTeleport, engine.ActionSafeTeleport:
+		return
+	}
+
+	highlight := color.Color(color.RGBA{0, 160, 0, 255})
+	if g.board.Rules.Monochrome {
+		highlight = color.Black
+	}
+	offsetX, offsetY := g.boardOffset()
+	x := float64(offsetX + cell.X*cellSize)
+	y := float64(offsetY + cell.Y*cellSize)
+	ebitenutil.DrawRect(screen, x, y, cellSize, 2, highlight)
+	ebitenutil.DrawRect(screen, x, y+cellSize-2, cellSize, 2, highlight)
+	ebitenutil.DrawRect(screen, x, y, 2, cellSize, highlight)
+	ebitenutil.DrawRect(screen, x+cellSize-2, y, 2, cellSize, highlight)
+}
@@ -0,0 +1,11 @@
+//go:build !js
+
+package daleks
+
+import "github.com/AaronSaikovski/godaleks/engine"
+
+// searchHint finds the best action for board. It runs on its own
+// goroutine, alongside the game.
+func searchHint(board engine.State) engine.Hint {
+	return engine.BestAction(board, engine.HintDepth)
+}
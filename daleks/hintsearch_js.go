@@ -0,0 +1,16 @@
+package daleks
+
+import (
+	"time"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+// searchHint finds the best action for board. The browser runs every
+// goroutine on one thread, so the search sleeps between batches of
+// positions to let frames be drawn while it thinks.
+func searchHint(board engine.State) engine.Hint {
+	return engine.BestActionYielding(board, engine.HintDepth, func() {
+		time.Sleep(time.Millisecond)
+	})
+}
@@ -0,0 +1,55 @@
+package daleks
+
+import "github.com/AaronSaikovski/godaleks/engine"
+
+// turnHistory keeps a snapshot of the board after every turn together with
+// the action that produced it. Casual games use it to step backwards and
+// forwards, and the actions up to the current turn make up the replay.
+// Snapshots include the RNG, so redoing a teleport lands in the same place.
+type turnHistory struct {
+	states  []engine.State
+	actions []engine.Action // actions[i] turned states[i] into states[i+1]
+	index   int             // Snapshot currently on the board
+	prefix  []engine.Action // Actions played before the history started
+}
+
+// reset starts a new history at the given state. prefix holds the actions
+// that led to it when resuming a game part way through.
+func (h *turnHistory) reset(state engine.State, prefix []engine.Action) {
+	h.states = []engine.State{state}
+	h.actions = nil
+	h.index = 0
+	h.prefix = prefix
+}
+
+// push records the state after a turn, discarding any redo snapshots
+func (h *turnHistory) push(action engine.Action, state engine.State) {
+	h.states = append(h.states[:h.index+1], state)
+	h.actions = append(h.actions[:h.index], action)
+	h.index = len(h.states) - 1
+}
+
+// undo returns the snapshot before the current one
+func (h *turnHistory) undo() (engine.State, bool) {
+	if h.index <= 0 {
+		return engine.State{}, false
+	}
+	h.index--
+	return h.states[h.index], true
+}
+
+// redo returns the snapshot after the current one
+func (h *turnHistory) redo() (engine.State, bool) {
+	if h.index+1 >= len(h.states) {
+		return engine.State{}, false
+	}
+	h.index++
+	return h.states[h.index], true
+}
+
+// played returns every action that led to the current snapshot
+func (h *turnHistory) played() []engine.Action {
+	played := make([]engine.Action, 0, len(h.prefix)+h.index)
+	played = append(played, h.prefix...)
+	return append(played, h.actions[:h.index]...)
+}
@@ -0,0 +1,137 @@
+package daleks
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+func TestTurnHistory(t *testing.T) {
+	start, _ := engine.NewState(engine.DefaultRules(), 7)
+	wait := engine.Action{Kind: engine.ActionWait}
+	teleport := engine.Action{Kind: engine.ActionTeleport}
+	prefix := []engine.Action{engine.MoveAction(1, 0)}
+
+	// Each step is an operation, the turn of the snapshot it should leave
+	// on the board and the actions a replay would hold afterwards
+	type step struct {
+		op         string // push, undo or redo
+		action     engine.Action
+		wantOK     bool
+		wantTurn   int
+		wantPlayed []engine.Action
+	}
+	tests := []struct {
+		name   string
+		prefix []engine.Action
+		steps  []step
+	}{
+		{
+			name: "nothing to undo or redo",
+			steps: []step{
+				{op: "undo", wantTurn: 0, wantPlayed: []engine.Action{}},
+				{op: "redo", wantTurn: 0, wantPlayed: []engine.Action{}},
+			},
+		},
+		{
+			name: "undo and redo",
+			steps: []step{
+				{op: "push", action: wait, wantOK: true, wantTurn: 1, wantPlayed: []engine.Action{wait}},
+				{op: "push", action: teleport, wantOK: true, wantTurn: 2, wantPlayed: []engine.Action{wait, teleport}},
+				{op: "undo", wantOK: true, wantTurn: 1, wantPlayed: []engine.Action{wait}},
+				{op: "undo", wantOK: true, wantTurn: 0, wantPlayed: []engine.Action{}},
+				{op: "undo", wantTurn: 0, wantPlayed: []engine.Action{}},
+				{op: "redo", wantOK: true, wantTurn: 1, wantPlayed: []engine.Action{wait}},
+				{op: "redo", wantOK: true, wantTurn: 2, wantPlayed: []engine.Action{wait, teleport}},
+				{op: "redo", wantTurn: 2, wantPlayed: []engine.Action{wait, teleport}},
+			},
+		},
+		{
+			name: "a new turn drops the redo snapshots",
+			steps: []step{
+				{op: "push", action: wait, wantOK: true, wantTurn: 1, wantPlayed: []engine.Action{wait}},
+				{op: "push", action: wait, wantOK: true, wantTurn: 2, wantPlayed: []engine.Action{wait, wait}},
+				{op: "undo", wantOK: true, wantTurn: 1, wantPlayed: []engine.Action{wait}},
+				{op: "push", action: teleport, wantOK: true, wantTurn: 2, wantPlayed: []engine.Action{wait, teleport}},
+				{op: "redo", wantTurn: 2, wantPlayed: []engine.Action{wait, teleport}},
+			},
+		},
+		{
+			name:   "resumed game keeps its earlier actions",
+			prefix: prefix,
+			steps: []step{
+				{op: "push", action: wait, wantOK: true, wantTurn: 1, wantPlayed: []engine.Action{prefix[0], wait}},
+				{op: "undo", wantOK: true, wantTurn: 0, wantPlayed: prefix},
+				{op: "undo", wantTurn: 0, wantPlayed: prefix},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h turnHistory
+			h.reset(start, tt.prefix)
+
+			// Snapshots are numbered by the turn counter, so each push
+			// makes a state one turn on from the current one
+			current := start
+			for i, st := range tt.steps {
+				ok := true
+				switch st.op {
+				case "push":
+					next := current.Clone()
+					next.Turns++
+					h.push(st.action, next)
+					current = next
+				case "undo":
+					current, ok = stepOrStay(h.undo, current)
+				case "redo":
+					current, ok = stepOrStay(h.redo, current)
+				}
+
+				if ok != st.wantOK {
+					t.Errorf("step %d (%s): ok %v, want %v", i+1, st.op, ok, st.wantOK)
+				}
+				if current.Turns != st.wantTurn {
+					t.Errorf("step %d (%s): on turn %d, want %d", i+1, st.op, current.Turns, st.wantTurn)
+				}
+				if got := h.played(); !slices.Equal(got, st.wantPlayed) {
+					t.Errorf("step %d (%s): played %v, want %v", i+1, st.op, got, st.wantPlayed)
+				}
+			}
+		})
+	}
+}
+
+// stepOrStay applies an undo or redo, keeping the current state when there
+// is nothing to step to
+func stepOrStay(step func() (engine.State, bool), current engine.State) (engine.State, bool) {
+	state, ok := step()
+	if !ok {
+		return current, false
+	}
+	return state, true
+}
+
+func TestTurnHistorySnapshotsAreIndependent(t *testing.T) {
+	start, _ := engine.NewState(engine.DefaultRules(), 7)
+	var h turnHistory
+	h.reset(start, nil)
+
+	next, events := engine.Step(start, engine.Action{Kind: engine.ActionTeleport})
+	if events == nil {
+		t.Fatal("teleport was refused")
+	}
+	h.push(engine.Action{Kind: engine.ActionTeleport}, next)
+
+	// Redoing a teleport lands where it did the first time
+	h.undo()
+	redone, _ := h.redo()
+	if redone.Player != next.Player || redone.RNG != next.RNG {
+		t.Errorf("redo put the player at %v, want %v", redone.Player, next.Player)
+	}
+	if undone, _ := h.undo(); undone.Player != start.Player {
+		t.Errorf("undo put the player at %v, want %v", undone.Player, start.Player)
+	}
+}
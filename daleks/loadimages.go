@@ -3,7 +3,9 @@ package daleks
 import (
 	"bytes"
 	"embed"
+	"errors"
 	"image"
+	"image/color"
 	_ "image/png" // Import image format support
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -14,33 +16,226 @@ var assets embed.FS
 
 // Daleks Images
 type DalekGameImages struct {
-	Human *ebiten.Image
-	Dalek *ebiten.Image
+	Human         *ebiten.Image
+	Dalek         *ebiten.Image
+	FastDalek     *ebiten.Image
+	ArmouredDalek *ebiten.Image
+	DamagedDalek  *ebiten.Image // Armoured dalek that has lost its armour
+	Emperor       *ebiten.Image // Spans 2x2 cells
+
+	// 1-bit versions for monochrome rules
+	HumanMono         *ebiten.Image
+	DalekMono         *ebiten.Image
+	FastDalekMono     *ebiten.Image
+	ArmouredDalekMono *ebiten.Image
+	DamagedDalekMono  *ebiten.Image
+	EmperorMono       *ebiten.Image
 }
 
-// loadImage loads an image from the assets directory
-func loadImage(filename string) (*ebiten.Image, error) {
+// loadImage loads an image from the assets directory, along with a 1-bit
+// version of it
+func loadImage(filename string) (*ebiten.Image, *ebiten.Image, error) {
 	data, err := assets.ReadFile("assets/" + filename)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	img, _, err := image.Decode(bytes.NewReader(data))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	return ebiten.NewImageFromImage(img), ebiten.NewImageFromImage(monochrome(img)), nil
+}
+
+// monochrome reduces an image to opaque black, opaque white and transparent
+// pixels
+func monochrome(img image.Image) image.Image {
+	bounds := img.Bounds()
+	out := image.NewNRGBA(bounds)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 0x80 {
+				continue
+			}
+			if color.GrayModel.Convert(c).(color.Gray).Y < 0x80 {
+				out.Set(x, y, color.Black)
+			} else {
+				out.Set(x, y, color.White)
+			}
+		}
 	}
-	return ebiten.NewImageFromImage(img), nil
+	return out
 }
 
-// / LoadImages initializes all crossword images and returns an error if any image fails to load
+// / LoadImages initializes all game images and returns an error for every image that fails to load
 func (images *DalekGameImages) LoadImages() error {
+	var errs []error
 	var err error
 
-	if images.Human, err = loadImage("human.png"); err != nil {
-		return err
+	if images.Human, images.HumanMono, err = loadImage("human.png"); err != nil {
+		errs = append(errs, err)
+	}
+	if images.Dalek, images.DalekMono, err = loadImage("dalek.png"); err != nil {
+		errs = append(errs, err)
+	}
+	if images.FastDalek, images.FastDalekMono, err = loadImage("dalek_fast.png"); err != nil {
+		errs = append(errs, err)
+	}
+	if images.ArmouredDalek, images.ArmouredDalekMono, err = loadImage("dalek_armoured.png"); err != nil {
+		errs = append(errs, err)
+	}
+	if images.DamagedDalek, images.DamagedDalekMono, err = loadImage("dalek_armoured_damaged.png"); err != nil {
+		errs = append(errs, err)
+	}
+	if images.Emperor, images.EmperorMono, err = loadImage("emperor.png"); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// fillPlaceholders replaces any image that failed to load with a generated one
+func (images *DalekGameImages) fillPlaceholders() {
+	// The generated sprites are already black and white
+	if images.Human == nil {
+		images.Human = createPlayerImage()
+		images.HumanMono = images.Human
+	}
+	if images.Dalek == nil {
+		images.Dalek = createDalekImage()
+		images.DalekMono = images.Dalek
+	}
+	if images.FastDalek == nil {
+		images.FastDalek = createFastDalekImage()
+		images.FastDalekMono = images.FastDalek
+	}
+	if images.ArmouredDalek == nil {
+		images.ArmouredDalek = createArmouredDalekImage(false)
+		images.ArmouredDalekMono = images.ArmouredDalek
+	}
+	if images.DamagedDalek == nil {
+		images.DamagedDalek = createArmouredDalekImage(true)
+		images.DamagedDalekMono = images.DamagedDalek
 	}
-	if images.Dalek, err = loadImage("dalek.png"); err != nil {
-		return err
+	if images.Emperor == nil {
+		images.Emperor = createEmperorImage()
+		images.EmperorMono = images.Emperor
 	}
+}
+
+// drawPixels sets every listed pixel that lies inside the image
+func drawPixels(img *ebiten.Image, pixels [][2]int, c color.Color) {
+	bounds := img.Bounds()
+	for _, p := range pixels {
+		if p[0] >= 0 && p[0] < bounds.Dx() && p[1] >= 0 && p[1] < bounds.Dy() {
+			img.Set(p[0], p[1], c)
+		}
+	}
+}
+
+// createPlayerImage creates a simple stick figure used when human.png is unavailable
+func createPlayerImage() *ebiten.Image {
+	size := cellSize - 2
+	img := ebiten.NewImage(size, size)
+	c := size / 2
+
+	var pixels [][2]int
+	// Head
+	for x := c - 1; x <= c+1; x++ {
+		for y := 1; y <= 3; y++ {
+			pixels = append(pixels, [2]int{x, y})
+		}
+	}
+	// Body
+	for y := 4; y <= 8; y++ {
+		pixels = append(pixels, [2]int{c, y})
+	}
+	// Arms and legs
+	for i := 1; i <= 3; i++ {
+		pixels = append(pixels,
+			[2]int{c - i, 5 + i/2}, [2]int{c + i, 5 + i/2},
+			[2]int{c - i, 8 + i}, [2]int{c + i, 8 + i},
+		)
+	}
+
+	drawPixels(img, pixels, color.Black)
+	return img
+}
+
+// createDalekImage creates a simple Dalek silhouette used when dalek.png is unavailable
+func createDalekImage() *ebiten.Image {
+	size := cellSize - 2
+	img := ebiten.NewImage(size, size)
+	c := size / 2
+
+	var pixels [][2]int
+	// Dome
+	for x := c - 2; x <= c+2; x++ {
+		for y := 1; y <= 3; y++ {
+			pixels = append(pixels, [2]int{x, y})
+		}
+	}
+	// Eye stalk
+	for x := c + 3; x <= c+5; x++ {
+		pixels = append(pixels, [2]int{x, 2})
+	}
+	// Skirt widens towards the base
+	for y := 4; y < size; y++ {
+		half := 2 + (y-4)/2
+		for x := c - half; x <= c+half; x++ {
+			pixels = append(pixels, [2]int{x, y})
+		}
+	}
+
+	drawPixels(img, pixels, color.Black)
+	return img
+}
+
+// createFastDalekImage creates the Dalek silhouette with speed lines behind
+// it, used when dalek_fast.png is unavailable
+func createFastDalekImage() *ebiten.Image {
+	img := createDalekImage()
+
+	var pixels [][2]int
+	for _, y := range []int{4, 7, 10} {
+		for x := 0; x < 3; x++ {
+			pixels = append(pixels, [2]int{x, y})
+		}
+	}
+
+	drawPixels(img, pixels, color.Black)
+	return img
+}
+
+// createArmouredDalekImage creates the Dalek silhouette between two shield
+// bars, broken once the armour is gone, used when the armoured sprites are
+// unavailable
+func createArmouredDalekImage(damaged bool) *ebiten.Image {
+	img := createDalekImage()
+	size := img.Bounds().Dx()
+
+	var pixels [][2]int
+	for y := 4; y < size; y++ {
+		if damaged && y%3 == 0 {
+			continue
+		}
+		pixels = append(pixels, [2]int{0, y}, [2]int{size - 1, y})
+	}
+
+	drawPixels(img, pixels, color.Black)
+	return img
+}
+
+// createEmperorImage creates the Dalek silhouette at twice the size, to
+// span the Emperor's 2x2 cells, used when emperor.png is unavailable
+func createEmperorImage() *ebiten.Image {
+	dalek := createDalekImage()
+	size := dalek.Bounds().Dx() * 2
+	img := ebiten.NewImage(size, size)
 
-	return nil
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(2, 2)
+	img.DrawImage(dalek, op)
+	return img
 }
@@ -0,0 +1,64 @@
+package daleks
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// drawDangerOverlay shades every cell a dalek could reach next turn. While
+// the mouse is over a cell the player could end the turn on, it also shows
+// ghost daleks where they would land and marks where they would crash.
+func (g *Game) drawDangerOverlay(screen *ebiten.Image) {
+	if !g.showDanger || g.busy() || g.intro != nil {
+		return
+	}
+
+	offsetX, offsetY := g.boardOffset()
+	cellOrigin := func(p Position) (float64, float64) {
+		return float64(offsetX + p.X*cellSize), float64(offsetY + p.Y*cellSize)
+	}
+
+	// Shade the danger zone, or dot it in black and white
+	for _, cell := range g.board.Danger() {
+		x, y := cellOrigin(cell)
+		if g.board.Rules.Monochrome {
+			ebitenutil.DrawRect(screen, x+cellSize/2-1, y+cellSize/2-1, 2, 2, color.Black)
+		} else {
+			ebitenutil.DrawRect(screen, x, y, cellSize, cellSize, color.RGBA{255, 120, 0, 60})
+		}
+	}
+
+	gridX, gridY, valid := g.screenToGrid(ebiten.CursorPosition())
+	hovered := Position{X: gridX, Y: gridY}
+	if !valid || !g.board.CanMoveTo(hovered) {
+		return
+	}
+	preview := g.board.PreviewMove(hovered)
+
+	// Ghost daleks where they would land
+	_, dalekImage := g.sprites()
+	for _, pos := range preview.Daleks {
+		x, y := getCenteredSpritePosition(pos.X, pos.Y, offsetX, offsetY, dalekImage)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(x, y)
+		op.ColorScale.ScaleAlpha(0.35)
+		screen.DrawImage(dalekImage, op)
+	}
+
+	// Cross out crash sites, and the hovered cell if it would be caught
+	crash := color.Color(color.RGBA{220, 0, 0, 255})
+	if g.board.Rules.Monochrome {
+		crash = color.Black
+	}
+	marks := preview.Crashes
+	if preview.Caught {
+		marks = append(marks, hovered)
+	}
+	for _, cell := range marks {
+		x, y := cellOrigin(cell)
+		ebitenutil.DrawLine(screen, x+2, y+2, x+cellSize-2, y+cellSize-2, crash)
+		ebitenutil.DrawLine(screen, x+cellSize-2, y+2, x+2, y+cellSize-2, crash)
+	}
+}
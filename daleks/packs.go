@@ -0,0 +1,360 @@
+package daleks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+	"github.com/AaronSaikovski/godaleks/levels"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	packDirName          = "packs"
+	packProgressFileName = "packs.json"
+
+	introWidth      = 70 // Characters per line of a level intro
+	packLevelsShown = 16 // Levels listed at once on the level select screen
+)
+
+// packProgress is how far the player has got through a level pack
+type packProgress struct {
+	Unlocked int   `json:"unlocked"`        // Highest level that can be chosen
+	Turns    []int `json:"turns,omitempty"` // Fewest turns each level was cleared in, 0 if never
+}
+
+// loadPacks returns the built-in level packs followed by those in the
+// packs folder of the data directory, and the files that failed to load
+func loadPacks() ([]*engine.Pack, []string) {
+	var packs []*engine.Pack
+	var failed []string
+	names := make(map[string]bool)
+
+	add := func(file string, data []byte, err error) {
+		var pack *engine.Pack
+		if err == nil {
+			pack, err = engine.ParsePack(data)
+		}
+		if err == nil {
+			err = checkPackFits(pack)
+		}
+		if err == nil && names[pack.Name] {
+			err = fmt.Errorf("a pack named %q is already loaded", pack.Name)
+		}
+		if err != nil {
+			log.Printf("level pack %s: %v", file, err)
+			failed = append(failed, filepath.Base(file))
+			return
+		}
+		names[pack.Name] = true
+		packs = append(packs, pack)
+	}
+
+	builtIn, _ := fs.Glob(levels.PackFiles, "packs/*.json")
+	for _, file := range builtIn {
+		data, err := levels.PackFiles.ReadFile(file)
+		add(file, data, err)
+	}
+
+	dir, err := dataPath(packDirName)
+	if err != nil {
+		return packs, failed
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		add(file, data, err)
+	}
+	return packs, failed
+}
+
+// checkPackFits rejects packs with levels too large for the window
+func checkPackFits(pack *engine.Pack) error {
+	for i := range pack.Levels {
+		rules := pack.LevelRules(i + 1)
+		if rules.Width > maxBoardWidth || rules.Height > maxBoardHeight {
+			return fmt.Errorf("level %d board is %dx%d, larger than the %dx%d that fits the window",
+				i+1, rules.Width, rules.Height, maxBoardWidth, maxBoardHeight)
+		}
+	}
+	return nil
+}
+
+// readPackProgress loads the progress through every level pack, by name
+func readPackProgress() (map[string]packProgress, error) {
+	progress := make(map[string]packProgress)
+
+	path, err := dataPath(packProgressFileName)
+	if err != nil {
+		return progress, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return progress, err
+	}
+
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return make(map[string]packProgress), fmt.Errorf("level pack progress file is corrupted: %w", err)
+	}
+	return progress, nil
+}
+
+// writePackProgress stores level pack progress in the data directory
+func writePackProgress(progress map[string]packProgress) error {
+	path, err := dataPath(packProgressFileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// subscribePacks unlocks the next level of a pack whenever one is cleared
+func (g *Game) subscribePacks(bus *engine.Bus) {
+	bus.Subscribe(engine.EventLevelCleared, func(e engine.Event) {
+		if g.board.Pack == nil || g.playback != nil {
+			return
+		}
+		g.packLevelCleared(g.board.Pack, e.Level, e.Count)
+	})
+}
+
+// packLevelCleared unlocks the level after the one cleared and keeps the
+// fewest turns it was cleared in
+func (g *Game) packLevelCleared(pack *engine.Pack, level, turns int) {
+	progress := g.packProgress[pack.Name]
+	progress.Unlocked = max(progress.Unlocked, min(level+1, len(pack.Levels)))
+	for len(progress.Turns) < len(pack.Levels) {
+		progress.Turns = append(progress.Turns, 0)
+	}
+	if best := progress.Turns[level-1]; best == 0 || turns < best {
+		progress.Turns[level-1] = turns
+	}
+
+	g.packProgress[pack.Name] = progress
+	if err := writePackProgress(g.packProgress); err != nil {
+		log.Printf("could not save level pack progress: %v", err)
+	}
+}
+
+// unlockedLevels returns how many levels of a pack can be chosen
+func (g *Game) unlockedLevels(pack *engine.Pack) int {
+	return min(max(g.packProgress[pack.Name].Unlocked, 1), len(pack.Levels))
+}
+
+// packSummary describes the level packs for the menu
+func (g *Game) packSummary() string {
+	summary := fmt.Sprintf("Press L for level packs (%d available)", len(g.packs))
+	if len(g.packsFailed) > 0 {
+		summary += fmt.Sprintf(", %d could not be loaded", len(g.packsFailed))
+	}
+	return summary
+}
+
+// openPackSelect shows the level select screen, with the furthest unlocked
+// level of the chosen pack selected
+func (g *Game) openPackSelect() {
+	g.returnToMenu()
+	g.state = StatePackSelect
+	if len(g.packs) > 0 {
+		g.packChoice = min(g.packChoice, len(g.packs)-1)
+		g.packLevel = g.unlockedLevels(g.packs[g.packChoice])
+	}
+}
+
+// startPackLevel starts a game on a level of a pack
+func (g *Game) startPackLevel(pack *engine.Pack, level int) {
+	g.resetBoard(engine.NewPackState(pack, randomSeed(), level))
+}
+
+// updatePackSelect handles the level select screen
+func (g *Game) updatePackSelect() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.returnToMenu()
+		return
+	}
+	if len(g.packs) == 0 {
+		return
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		g.packChoice = (g.packChoice + len(g.packs) - 1) % len(g.packs)
+		g.packLevel = g.unlockedLevels(g.packs[g.packChoice])
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		g.packChoice = (g.packChoice + 1) % len(g.packs)
+		g.packLevel = g.unlockedLevels(g.packs[g.packChoice])
+	}
+
+	pack := g.packs[g.packChoice]
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft):
+		g.packLevel = max(g.packLevel-1, 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowRight):
+		g.packLevel = min(g.packLevel+1, g.unlockedLevels(pack))
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		g.startPackLevel(pack, g.packLevel)
+	}
+}
+
+// drawPackSelect draws the level select screen
+func (g *Game) drawPackSelect(screen *ebiten.Image) {
+	title := "LEVEL PACKS"
+	text.Draw(screen, title, basicfont.Face7x13, screenWidth/2-len(title)*3, 60, color.Black)
+
+	help := "UP/DOWN: pack  LEFT/RIGHT: level  SPACE: play  ESC: back"
+	text.Draw(screen, help, basicfont.Face7x13, screenWidth/2-len(help)*3, screenHeight-45, color.Black)
+	if dir, err := dataPath(packDirName); err == nil {
+		text.Draw(screen, "Add your own packs to "+dir, basicfont.Face7x13, 50, screenHeight-25, color.Black)
+	}
+	if len(g.packsFailed) > 0 {
+		failed := "Could not load: " + strings.Join(g.packsFailed, ", ")
+		text.Draw(screen, failed, basicfont.Face7x13, 50, screenHeight-10, color.Black)
+	}
+
+	if len(g.packs) == 0 {
+		text.Draw(screen, "No level packs found", basicfont.Face7x13, 50, 100, color.Black)
+		return
+	}
+
+	y := 100
+	for i, pack := range g.packs {
+		marker := "  "
+		if i == g.packChoice {
+			marker = "> "
+		}
+		line := fmt.Sprintf("%s%s  (%d/%d unlocked)  %s",
+			marker, pack.Name, g.unlockedLevels(pack), len(pack.Levels), pack.Description)
+		text.Draw(screen, line, basicfont.Face7x13, 50, y, color.Black)
+		y += 17
+	}
+
+	pack := g.packs[g.packChoice]
+	progress := g.packProgress[pack.Name]
+	unlocked := g.unlockedLevels(pack)
+	y += 17
+
+	first := max(0, min(g.packLevel-packLevelsShown/2, len(pack.Levels)-packLevelsShown))
+	last := min(first+packLevelsShown, len(pack.Levels))
+	for i := first; i < last; i++ {
+		level := pack.Levels[i]
+		marker := "  "
+		if i+1 == g.packLevel {
+			marker = "> "
+		}
+
+		line := fmt.Sprintf("%s%2d. %s", marker, i+1, level.Name)
+		switch {
+		case i+1 > unlocked:
+			line += "  (locked)"
+		case i < len(progress.Turns) && progress.Turns[i] > 0:
+			line += fmt.Sprintf("  cleared in %d turns", progress.Turns[i])
+			if level.Par > 0 {
+				line += fmt.Sprintf(" (par %d)", level.Par)
+			}
+		}
+		text.Draw(screen, line, basicfont.Face7x13, 70, y, color.Black)
+		y += 17
+	}
+}
+
+// packStatus describes the pack level being played for the HUD
+func (g *Game) packStatus() string {
+	level := g.board.Pack.Level(g.board.Level)
+	status := fmt.Sprintf("Turns: %d", g.board.Turns)
+	if g.board.Rules.MaxTurns > 0 {
+		status += fmt.Sprintf("/%d", g.board.Rules.MaxTurns)
+	}
+	if level.Par > 0 {
+		status += fmt.Sprintf("  Par: %d", level.Par)
+	}
+	return status
+}
+
+// showIntro holds the game on a level's intro until a key is pressed
+func (g *Game) showIntro(level int) {
+	if g.board.Pack == nil || g.playback != nil {
+		return
+	}
+
+	l := g.board.Pack.Level(level)
+	title := fmt.Sprintf("Level %d of %d", level, len(g.board.Pack.Levels))
+	if l.Name != "" {
+		title += ": " + l.Name
+	}
+	g.intro = append([]string{title, ""}, wrapText(l.Intro, introWidth)...)
+	if l.Intro != "" {
+		g.intro = append(g.intro, "")
+	}
+	g.intro = append(g.intro, "Press any key to begin")
+}
+
+// updateIntro dismisses the intro on any key or click
+func (g *Game) updateIntro() {
+	if len(inpututil.AppendJustPressedKeys(nil)) > 0 || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		g.intro = nil
+	}
+}
+
+// drawIntro draws the level intro in a box in the middle of the screen
+func (g *Game) drawIntro(screen *ebiten.Image) {
+	width := 0
+	for _, line := range g.intro {
+		width = max(width, len(line))
+	}
+
+	boxWidth := float64(width*7 + 40)
+	boxHeight := float64(len(g.intro)*17 + 30)
+	x := (screenWidth - boxWidth) / 2
+	y := (screenHeight - boxHeight) / 2
+	ebitenutil.DrawRect(screen, x, y, boxWidth, boxHeight, color.Black)
+	ebitenutil.DrawRect(screen, x+2, y+2, boxWidth-4, boxHeight-4, color.White)
+
+	for i, line := range g.intro {
+		text.Draw(screen, line, basicfont.Face7x13, int(x)+20, int(y)+30+i*17, color.Black)
+	}
+}
+
+// wrapText splits s into lines of at most width characters, breaking
+// between words
+func wrapText(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
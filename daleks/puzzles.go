@@ -0,0 +1,181 @@
+package daleks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+	"github.com/AaronSaikovski/godaleks/levels"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const puzzleProgressFileName = "puzzles.json"
+
+// puzzleResult is the best solution found for a puzzle
+type puzzleResult struct {
+	Stars int `json:"stars"`
+	Turns int `json:"turns"`
+}
+
+// loadPuzzles returns the built-in puzzles
+func loadPuzzles() []engine.Puzzle {
+	puzzles, err := levels.Puzzles()
+	if err != nil {
+		log.Printf("puzzles unavailable: %v", err)
+	}
+	return puzzles
+}
+
+// readPuzzleProgress loads the best result of every solved puzzle, by name
+func readPuzzleProgress() (map[string]puzzleResult, error) {
+	progress := make(map[string]puzzleResult)
+
+	path, err := dataPath(puzzleProgressFileName)
+	if err != nil {
+		return progress, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return progress, err
+	}
+
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return make(map[string]puzzleResult), fmt.Errorf("puzzle progress file is corrupted: %w", err)
+	}
+	return progress, nil
+}
+
+// writePuzzleProgress stores puzzle results in the data directory
+func writePuzzleProgress(progress map[string]puzzleResult) error {
+	path, err := dataPath(puzzleProgressFileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// puzzleSummary describes puzzle progress for the menu
+func (g *Game) puzzleSummary() string {
+	stars := 0
+	for _, p := range g.puzzles {
+		stars += g.puzzleProgress[p.Name].Stars
+	}
+	return fmt.Sprintf("Press Z for puzzles (%d/%d solved, %d/%d stars)",
+		len(g.puzzleProgress), len(g.puzzles), stars, 3*len(g.puzzles))
+}
+
+// firstUnsolvedPuzzle returns the puzzle the menu starts, wrapping to the
+// first once all are solved
+func (g *Game) firstUnsolvedPuzzle() int {
+	for i, p := range g.puzzles {
+		if _, ok := g.puzzleProgress[p.Name]; !ok {
+			return i
+		}
+	}
+	return 0
+}
+
+// findPuzzle returns the index of the named puzzle
+func (g *Game) findPuzzle(name string) (int, bool) {
+	for i, p := range g.puzzles {
+		if p.Name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// startPuzzle sets up a puzzle's board
+func (g *Game) startPuzzle(i int) {
+	if i < 0 || i >= len(g.puzzles) {
+		return
+	}
+	g.resetBoard(g.puzzles[i].Start())
+	g.puzzle = i
+	g.inPuzzle = true
+}
+
+// puzzleScore returns how well the puzzle being played was solved
+func (g *Game) puzzleScore() puzzleResult {
+	p := g.puzzles[g.puzzle]
+	return puzzleResult{Stars: p.Stars(g.board.Turns), Turns: g.board.Turns}
+}
+
+// showPuzzleResult shows the stars earned for a solved puzzle
+func (g *Game) showPuzzleResult() {
+	if g.board.Phase != engine.PhaseWin {
+		return
+	}
+	result := g.puzzleScore()
+	g.gameOverMessage = fmt.Sprintf("Puzzle solved in %d turns!  Stars: %s",
+		result.Turns, strings.Repeat("*", result.Stars)+strings.Repeat("-", 3-result.Stars))
+}
+
+// recordPuzzle keeps the best result of a solved puzzle
+func (g *Game) recordPuzzle() {
+	if g.board.Phase != engine.PhaseWin {
+		return
+	}
+
+	name := g.puzzleName()
+	result := g.puzzleScore()
+	if best, ok := g.puzzleProgress[name]; ok && best.Turns <= result.Turns {
+		return
+	}
+	g.puzzleProgress[name] = result
+	if err := writePuzzleProgress(g.puzzleProgress); err != nil {
+		log.Printf("could not save puzzle progress: %v", err)
+	}
+}
+
+// puzzleName returns the name of the puzzle being played, if any
+func (g *Game) puzzleName() string {
+	if !g.inPuzzle {
+		return ""
+	}
+	return g.puzzles[g.puzzle].Name
+}
+
+// puzzleStatus describes the puzzle being played for the HUD
+func (g *Game) puzzleStatus() string {
+	p := g.puzzles[g.puzzle]
+	return fmt.Sprintf("Puzzle %d/%d: %s  Turns: %d/%d  Par: %d",
+		g.puzzle+1, len(g.puzzles), p.Name, g.board.Turns, p.MaxTurns, p.Par)
+}
+
+// updatePuzzleEndKeys moves on from a solved or failed puzzle
+func (g *Game) updatePuzzleEndKeys() {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace):
+		g.startPuzzle(g.puzzle)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		g.returnToMenu()
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		switch {
+		case g.board.Phase != engine.PhaseWin:
+			g.startPuzzle(g.puzzle)
+		case g.puzzle+1 < len(g.puzzles):
+			g.startPuzzle(g.puzzle + 1)
+		default:
+			g.returnToMenu()
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package daleks
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	replayDirName  = "replays"
+	lastReplayName = "last.replay"
+	playbackDelay  = 0.3 // Seconds between replayed actions at 1x
+)
+
+// playback drives the game from a recorded replay
+type playback struct {
+	replay engine.Replay
+	next   int     // Index of the next action to play
+	speed  int     // Simulation speed multiplier: 1, 2 or 4
+	paused bool    // Whether playback is paused
+	wait   float64 // Seconds left before the next action
+	err    string  // Why playback stopped early
+}
+
+// writeReplayFile stores a replay at path
+func writeReplayFile(path string, replay engine.Replay) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := engine.WriteReplay(f, replay); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readReplayFile loads a replay from path
+func readReplayFile(path string) (engine.Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return engine.Replay{}, err
+	}
+	defer f.Close()
+	return engine.ReadReplay(f)
+}
+
+// hasLastReplay reports whether a recorded game is available to watch
+func hasLastReplay() bool {
+	path, err := dataPath(lastReplayName)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// recordReplay writes the finished game to the replays directory and keeps
+// a copy as the last replay
+func (g *Game) recordReplay() {
+	g.recordRequested()
+	replay := engine.NewReplay(g.board, g.history.played())
+
+	dir, err := dataPath(replayDirName)
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		log.Printf("could not record replay: %v", err)
+		return
+	}
+
+	name := fmt.Sprintf("%s-%d.replay", time.Now().Format("20060102-150405"), g.board.Seed)
+	if err := writeReplayFile(filepath.Join(dir, name), replay); err != nil {
+		log.Printf("could not record replay: %v", err)
+		return
+	}
+
+	if last, err := dataPath(lastReplayName); err == nil {
+		if err := writeReplayFile(last, replay); err != nil {
+			log.Printf("could not record replay: %v", err)
+		}
+	}
+}
+
+// recordRequested writes the replay to the file given with --record
+func (g *Game) recordRequested() {
+	if g.recordFile == "" {
+		return
+	}
+	replay := engine.NewReplay(g.board, g.history.played())
+	if err := writeReplayFile(g.recordFile, replay); err != nil {
+		log.Printf("could not record replay: %v", err)
+	}
+}
+
+// watchLastReplay starts playback of the most recently recorded game
+func (g *Game) watchLastReplay() {
+	path, err := dataPath(lastReplayName)
+	if err == nil {
+		var replay engine.Replay
+		if replay, err = readReplayFile(path); err == nil {
+			g.startPlayback(replay)
+			return
+		}
+	}
+	g.menuMessage = "Could not load replay: " + err.Error()
+}
+
+// startPlayback resets the board to the replay's start and plays it back
+func (g *Game) startPlayback(replay engine.Replay) {
+	g.resetBoard(replay.Start())
+	g.playback = &playback{
+		replay: replay,
+		speed:  1,
+		wait:   playbackDelay,
+	}
+	g.intro = nil // Replays skip pack level intros
+}
+
+// stopPlayback leaves playback and returns to the menu
+func (g *Game) stopPlayback() {
+	g.playback = nil
+	g.returnToMenu()
+}
+
+// updatePlayback plays the next recorded action once the previous one has
+// finished animating
+func (g *Game) updatePlayback(deltaTime float64) {
+	p := g.playback
+	if p.paused || g.state != StatePlaying || g.busy() || p.next >= len(p.replay.Actions) {
+		return
+	}
+
+	p.wait -= deltaTime
+	if p.wait > 0 {
+		return
+	}
+
+	action := p.replay.Actions[p.next]
+	p.next++
+	p.wait = playbackDelay
+
+	if !g.act(action) {
+		p.err = fmt.Sprintf("Replay diverged at action %d", p.next)
+		p.paused = true
+	}
+}
+
+// updatePlaybackKeys handles speed, pause and stop during playback
+func (g *Game) updatePlaybackKeys() {
+	p := g.playback
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.Key1):
+		p.speed = 1
+	case inpututil.IsKeyJustPressed(ebiten.Key2):
+		p.speed = 2
+	case inpututil.IsKeyJustPressed(ebiten.Key4):
+		p.speed = 4
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		p.paused = !p.paused
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.stopPlayback()
+	}
+}
+
+// playbackStatus describes playback progress for the HUD
+func (g *Game) playbackStatus() string {
+	p := g.playback
+	if p.err != "" {
+		return p.err + "  (ESC to stop)"
+	}
+
+	status := fmt.Sprintf("REPLAY %dx  Action %d/%d", p.speed, p.next, len(p.replay.Actions))
+	switch {
+	case p.paused:
+		status += "  PAUSED"
+	case p.next >= len(p.replay.Actions) && !g.busy():
+		status += "  FINISHED"
+	}
+	return status + "  (1/2/4 speed, P pause, ESC stop)"
+}
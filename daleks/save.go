@@ -0,0 +1,142 @@
+package daleks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+const (
+	saveFormat   = "godaleks-save"
+	saveVersion  = 1
+	saveFileName = "save.json"
+)
+
+// saveFile is the versioned envelope written to disk
+type saveFile struct {
+	Format   string          `json:"format"`
+	Version  int             `json:"version"`
+	Checksum uint32          `json:"checksum"` // CRC-32 of Game in compact form
+	Game     json.RawMessage `json:"game"`
+}
+
+// savedGame is everything needed to resume a game in progress
+type savedGame struct {
+	Board    engine.State    `json:"board"`
+	ShowGrid bool            `json:"showGrid"`
+	Casual   bool            `json:"casual"`
+	UsedUndo bool            `json:"usedUndo"`
+	Daily    string          `json:"daily,omitempty"`    // Date of the daily challenge being played
+	Puzzle   string          `json:"puzzle,omitempty"`   // Name of the puzzle being played
+	Imported bool            `json:"imported,omitempty"` // Board was imported from text
+	Hints    int             `json:"hints,omitempty"`    // Hints asked for so far
+	Actions  []engine.Action `json:"actions"`            // Every action so far, for the replay
+}
+
+// encodeSave serializes a game into the save file format
+func encodeSave(game savedGame) ([]byte, error) {
+	payload, err := json.Marshal(game)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(saveFile{
+		Format:   saveFormat,
+		Version:  saveVersion,
+		Checksum: crc32.ChecksumIEEE(payload),
+		Game:     payload,
+	}, "", "  ")
+}
+
+// decodeSave parses and validates a save file
+func decodeSave(data []byte) (savedGame, error) {
+	var game savedGame
+	var file saveFile
+
+	if err := json.Unmarshal(data, &file); err != nil {
+		return game, fmt.Errorf("save file is corrupted: %w", err)
+	}
+	if file.Format != saveFormat {
+		return game, errors.New("not a GoDaleks save file")
+	}
+	if file.Version != saveVersion {
+		return game, fmt.Errorf("save file version %d is not supported (expected %d)", file.Version, saveVersion)
+	}
+
+	// Indenting the file indents the game inside it too, so the checksum is
+	// taken over the compact form
+	var payload bytes.Buffer
+	if err := json.Compact(&payload, file.Game); err != nil {
+		return game, fmt.Errorf("save file is corrupted: %w", err)
+	}
+	if crc32.ChecksumIEEE(payload.Bytes()) != file.Checksum {
+		return game, errors.New("save file is corrupted: checksum mismatch")
+	}
+
+	if err := json.Unmarshal(file.Game, &game); err != nil {
+		return game, fmt.Errorf("save file is corrupted: %w", err)
+	}
+	if game.Board.Phase != engine.PhasePlaying {
+		return game, errors.New("saved game has already finished")
+	}
+	if err := game.Board.Validate(); err != nil {
+		return game, fmt.Errorf("save file is corrupted: %w", err)
+	}
+	return game, nil
+}
+
+// writeSave stores the game in the data directory
+func writeSave(game savedGame) error {
+	path, err := dataPath(saveFileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := encodeSave(game)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves half a save
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readSave loads the saved game from the data directory
+func readSave() (savedGame, error) {
+	path, err := dataPath(saveFileName)
+	if err != nil {
+		return savedGame{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return savedGame{}, err
+	}
+	return decodeSave(data)
+}
+
+// hasSave reports whether a save file exists
+func hasSave() bool {
+	path, err := dataPath(saveFileName)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// removeSave deletes the save file once its game has ended
+func removeSave() {
+	if path, err := dataPath(saveFileName); err == nil {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,95 @@
+package daleks
+
+import (
+	"encoding/json"
+	"hash/crc32"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+// testGame returns a saved game a few turns into a seeded board
+func testGame(t *testing.T) savedGame {
+	t.Helper()
+	board, _ := engine.NewState(engine.DefaultRules(), 42)
+	actions := []engine.Action{{Kind: engine.ActionWait}, {Kind: engine.ActionTeleport}}
+	for _, a := range actions {
+		next, events := engine.Step(board, a)
+		if events == nil {
+			t.Fatalf("action %+v was refused", a)
+		}
+		board = next
+	}
+	if board.Phase != engine.PhasePlaying {
+		t.Fatalf("test game ended early")
+	}
+	return savedGame{Board: board, Casual: true, UsedUndo: true, Hints: 2, Actions: actions}
+}
+
+// envelope writes a save file around payload, with its checksum adjusted by
+// checksumDelta
+func envelope(format string, version int, payload []byte, checksumDelta uint32) []byte {
+	data, _ := json.Marshal(saveFile{
+		Format:   format,
+		Version:  version,
+		Checksum: crc32.ChecksumIEEE(payload) + checksumDelta,
+		Game:     payload,
+	})
+	return data
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	game := testGame(t)
+	data, err := encodeSave(game)
+	if err != nil {
+		t.Fatalf("encodeSave: %v", err)
+	}
+	got, err := decodeSave(data)
+	if err != nil {
+		t.Fatalf("decodeSave: %v", err)
+	}
+	if !reflect.DeepEqual(got, game) {
+		t.Errorf("round trip gave\n%+v\nwant\n%+v", got, game)
+	}
+}
+
+func TestDecodeSaveErrors(t *testing.T) {
+	game := testGame(t)
+	payload, _ := json.Marshal(game)
+	valid, _ := encodeSave(game)
+
+	withBoard := func(edit func(b *engine.State)) []byte {
+		g := game
+		g.Board = game.Board.Clone()
+		edit(&g.Board)
+		p, _ := json.Marshal(g)
+		return envelope(saveFormat, saveVersion, p, 0)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "corrupted"},
+		{"truncated", valid[:len(valid)/2], "corrupted"},
+		{"wrong format", envelope("something-else", saveVersion, payload, 0), "not a GoDaleks save"},
+		{"wrong version", envelope(saveFormat, saveVersion+1, payload, 0), "version 2"},
+		{"bad checksum", envelope(saveFormat, saveVersion, payload, 1), "checksum"},
+		{"game is not an object", envelope(saveFormat, saveVersion, []byte(`42`), 0), "corrupted"},
+		{"finished", withBoard(func(b *engine.State) { b.Phase = engine.PhaseGameOver }), "already finished"},
+		{"player off the board", withBoard(func(b *engine.State) { b.Player.X = -1 }), "corrupted"},
+		{"invalid rules", withBoard(func(b *engine.State) { b.Rules.Width = 0 }), "corrupted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeSave(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
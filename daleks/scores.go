@@ -0,0 +1,176 @@
+package daleks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+const (
+	scoresFileName = "scores.json"
+	maxHighScores  = 10 // Entries kept per preset
+	milestoneEvery = 10 // Levels between depth milestones in games without a final level
+)
+
+// highScore is one finished game in the local high score table
+type highScore struct {
+	Score    int          `json:"score"`
+	Level    int          `json:"level"`
+	Phase    engine.Phase `json:"phase"`
+	Preset   string       `json:"preset"`
+	Seed     uint64       `json:"seed"`
+	UsedUndo bool         `json:"usedUndo"`
+	Hints    int          `json:"hints,omitempty"`
+	Date     time.Time    `json:"date"`
+}
+
+// String describes the entry for the menu and game over screen
+func (h highScore) String() string {
+	flag := ""
+	if h.UsedUndo {
+		flag = "*"
+	}
+	hints := ""
+	if h.Hints > 0 {
+		hints = fmt.Sprintf(", %d hints", h.Hints)
+	}
+	return fmt.Sprintf("%d%s (level %d%s, %s)", h.Score, flag, h.Level, hints, h.Date.Format("2006-01-02"))
+}
+
+// readHighScores loads the high score table. A missing file is an empty table.
+func readHighScores() ([]highScore, error) {
+	path, err := dataPath(scoresFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var scores []highScore
+	if err := json.Unmarshal(data, &scores); err != nil {
+		return nil, fmt.Errorf("high score file is corrupted: %w", err)
+	}
+	return scores, nil
+}
+
+// writeHighScores stores the high score table in the data directory
+func writeHighScores(scores []highScore) error {
+	path, err := dataPath(scoresFileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(scores, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// addHighScore inserts entry into the table, keeping the best scores of
+// each preset. It returns the entry's rank within its preset, starting at
+// 1, or 0 if it did not make the table.
+func addHighScore(scores []highScore, entry highScore) ([]highScore, int) {
+	scores = append(scores, entry)
+	slices.SortStableFunc(scores, func(a, b highScore) int {
+		return b.Score - a.Score
+	})
+
+	kept := scores[:0]
+	counts := make(map[string]int)
+	rank := 0
+	for _, s := range scores {
+		if counts[s.Preset] == maxHighScores {
+			continue
+		}
+		counts[s.Preset]++
+		if s == entry {
+			rank = counts[s.Preset]
+		}
+		kept = append(kept, s)
+	}
+	return kept, rank
+}
+
+// bestScore returns the top entry for a preset
+func bestScore(scores []highScore, preset string) (highScore, bool) {
+	// The table is kept sorted, best first
+	for _, s := range scores {
+		if s.Preset == preset {
+			return s, true
+		}
+	}
+	return highScore{}, false
+}
+
+// bestLevel returns the deepest level reached in the table for a preset
+func bestLevel(scores []highScore, preset string) int {
+	best := 0
+	for _, s := range scores {
+		if s.Preset == preset {
+			best = max(best, s.Level)
+		}
+	}
+	return best
+}
+
+// checkMilestones announces depth milestones and new personal bests when a
+// level starts in a game without a final level
+func (g *Game) checkMilestones(level int) {
+	if g.board.Rules.MaxLevel != 0 || level == g.board.StartLevel {
+		return
+	}
+
+	best := bestLevel(g.highScores, g.board.Rules.Name)
+	switch {
+	case level%milestoneEvery == 0:
+		g.showNotice(fmt.Sprintf("Depth milestone: level %d!", level), 2.5)
+	case best > 0 && level == best+1:
+		g.showNotice(fmt.Sprintf("New personal best: level %d!", level), 2.5)
+	}
+}
+
+// recordHighScore adds the finished game to the high score table and
+// remembers where it placed
+func (g *Game) recordHighScore() {
+	scores, err := readHighScores()
+	if err != nil {
+		log.Printf("could not read high scores: %v", err)
+		return
+	}
+
+	scores, g.highScoreRank = addHighScore(scores, highScore{
+		Score:    g.board.Score,
+		Level:    g.board.Level,
+		Phase:    g.board.Phase,
+		Preset:   g.board.Rules.Name,
+		Seed:     g.board.Seed,
+		UsedUndo: g.usedUndo,
+		Hints:    g.hints,
+		Date:     time.Now().UTC().Truncate(time.Second),
+	})
+	g.highScores = scores
+
+	if g.highScoreRank > 0 {
+		if err := writeHighScores(scores); err != nil {
+			log.Printf("could not save high scores: %v", err)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package daleks
 import (
 	"bytes"
 	_ "embed"
+	"fmt"
 
+	"github.com/AaronSaikovski/godaleks/engine"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/audio/wav"
 )
@@ -23,6 +25,12 @@ var gameoverData []byte
 //go:embed assets/gamestart.wav
 var gamestartData []byte
 
+//go:embed assets/emperor.wav
+var emperorData []byte
+
+//go:embed assets/emperor_destroyed.wav
+var emperorDestroyedData []byte
+
 const (
 	sampleRate = 44100
 )
@@ -32,7 +40,14 @@ type SoundPlayer struct {
 	sounds       map[string]*audio.Player
 }
 
-func NewSoundPlayer() (*SoundPlayer, error) {
+func NewSoundPlayer() (player *SoundPlayer, err error) {
+	// audio.NewContext panics instead of returning an error
+	defer func() {
+		if r := recover(); r != nil {
+			player, err = nil, fmt.Errorf("audio: %v", r)
+		}
+	}()
+
 	audioContext := audio.NewContext(sampleRate)
 
 	// Initialize sound map
@@ -45,6 +60,8 @@ func NewSoundPlayer() (*SoundPlayer, error) {
 		"crash":       crashData,
 		"gamestart":   gamestartData,
 		"gameover":    gameoverData,
+		"emperor":     emperorData,
+		"emperorDown": emperorDestroyedData,
 	}
 
 	for name, data := range soundData {
@@ -67,6 +84,12 @@ func NewSoundPlayer() (*SoundPlayer, error) {
 	}, nil
 }
 
+// NewSilentPlayer returns a player that accepts every sound and plays none,
+// for machines without audio
+func NewSilentPlayer() *SoundPlayer {
+	return &SoundPlayer{sounds: map[string]*audio.Player{}}
+}
+
 func (s *SoundPlayer) Play(name string) {
 	if player, exists := s.sounds[name]; exists {
 		player.Rewind()
@@ -79,3 +102,28 @@ func (s *SoundPlayer) Close() {
 		player.Close()
 	}
 }
+
+// Subscribe plays the matching sound effect for game events
+func (s *SoundPlayer) Subscribe(bus *engine.Bus) {
+	sounds := map[engine.EventKind]string{
+		engine.EventDalekHitScrap:    "crash",
+		engine.EventDaleksCollided:   "crash",
+		engine.EventDalekDamaged:     "crash",
+		engine.EventEmperorHit:       "crash",
+		engine.EventEmperorSummoned:  "emperor",
+		engine.EventEmperorDestroyed: "emperorDown",
+		engine.EventPlayerTeleported: "teleport",
+		engine.EventPlayerCaught:     "gameover",
+		engine.EventGameWon:          "gameover",
+		engine.EventLevelStarted:     "gamestart",
+	}
+	for kind, name := range sounds {
+		bus.Subscribe(kind, func(engine.Event) { s.Play(name) })
+	}
+
+	bus.Subscribe(engine.EventScrewdriverFired, func(e engine.Event) {
+		if len(e.Targets) > 0 {
+			s.Play("screwdriver")
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package daleks
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+// Stats counts what happened during a game, fed from the event bus
+type Stats struct {
+	CrashKills       int // Daleks destroyed crashing into each other
+	ScrapKills       int // Daleks destroyed running into scrap
+	ScrewdriverKills int // Daleks destroyed by the sonic screwdriver
+	Teleports        int
+	SafeTeleports    int
+	LastStands       int
+	LevelsCleared    int
+	BonusPoints      int // Points from level and Last Stand bonuses
+}
+
+// Subscribe updates the statistics from game events
+func (s *Stats) Subscribe(bus *engine.Bus) {
+	bus.Subscribe(engine.EventDaleksCollided, func(e engine.Event) { s.CrashKills += e.Count })
+	bus.Subscribe(engine.EventDalekHitScrap, func(e engine.Event) { s.ScrapKills += e.Count })
+	bus.Subscribe(engine.EventScrewdriverFired, func(e engine.Event) { s.ScrewdriverKills += e.Count })
+	bus.Subscribe(engine.EventPlayerTeleported, func(e engine.Event) {
+		if e.Safe {
+			s.SafeTeleports++
+		} else {
+			s.Teleports++
+		}
+	})
+	bus.Subscribe(engine.EventLastStandStarted, func(engine.Event) { s.LastStands++ })
+	bus.Subscribe(engine.EventLastStandEnded, func(e engine.Event) { s.BonusPoints += e.Points })
+	bus.Subscribe(engine.EventLevelCleared, func(e engine.Event) {
+		s.LevelsCleared++
+		s.BonusPoints += e.Points
+	})
+}
+
+// Summary describes the statistics on a single line
+func (s *Stats) Summary() string {
+	return fmt.Sprintf("Destroyed: %d crashed, %d on scrap, %d by screwdriver  Teleports: %d + %d safe",
+		s.CrashKills, s.ScrapKills, s.ScrewdriverKills, s.Teleports, s.SafeTeleports)
+}
+
+// subscribeEventLog writes every event to the log while debugging is on
+func (g *Game) subscribeEventLog(bus *engine.Bus) {
+	bus.SubscribeAll(func(e engine.Event) {
+		if g.logEvents {
+			log.Printf("event: %s", e)
+		}
+	})
+}
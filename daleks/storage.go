@@ -0,0 +1,30 @@
+package daleks
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// dataDir returns the directory used for saves and other local game data,
+// creating it if needed
+func dataDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(base, "godaleks")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// dataPath returns the full path of a file inside the data directory
+func dataPath(name string) (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
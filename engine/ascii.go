@@ -0,0 +1,236 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Cell symbols used by the text board format, as on the BSD robots screen
+const (
+	SymbolPlayer    = '@'
+	SymbolDalek     = '+'
+	SymbolFastDalek = 'F'
+	SymbolArmoured  = 'A'
+	SymbolDamaged   = 'a' // Armoured dalek that has lost its armour
+	SymbolEmperor   = 'E' // Fills each of the Emperor's cells
+	SymbolScrap     = '*'
+	SymbolEmpty     = '.'
+)
+
+// FormatASCII renders the board as text: a header with the seed, level,
+// score and items, and the Emperor's health when it is on the board, then
+// one line per row of the board
+func FormatASCII(s State) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Seed: %d  Level: %d  Score: %d  Turns: %d\n", s.Seed, s.Level, s.Score, s.Turns)
+	fmt.Fprintf(&b, "Teleports: %d  Safe: %d  Screwdrivers: %d  LastStands: %d",
+		s.Teleports, s.SafeTeleports, s.Screwdrivers, s.LastStands)
+	for _, dalek := range s.Daleks {
+		if dalek.Kind == DalekEmperor {
+			fmt.Fprintf(&b, "  Emperor: %d", dalek.Health)
+			break
+		}
+	}
+	b.WriteByte('\n')
+
+	grid := make([][]byte, s.Rules.Height)
+	for y := range grid {
+		grid[y] = []byte(strings.Repeat(string(SymbolEmpty), s.Rules.Width))
+	}
+	set := func(p Position, c byte) {
+		if s.InBounds(p) {
+			grid[p.Y][p.X] = c
+		}
+	}
+	for _, scrap := range s.Scraps {
+		set(scrap, SymbolScrap)
+	}
+	for _, dalek := range s.Daleks {
+		switch {
+		case dalek.Kind == DalekEmperor:
+			for _, cell := range dalek.Cells() {
+				set(cell, SymbolEmperor)
+			}
+		case dalek.Kind == DalekFast:
+			set(dalek.Pos, SymbolFastDalek)
+		case dalek.Kind == DalekArmoured && dalek.Health > 0:
+			set(dalek.Pos, SymbolArmoured)
+		case dalek.Kind == DalekArmoured:
+			set(dalek.Pos, SymbolDamaged)
+		default:
+			set(dalek.Pos, SymbolDalek)
+		}
+	}
+	set(s.Player, SymbolPlayer)
+
+	for _, row := range grid {
+		b.Write(row)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ParseASCII reads a board written by FormatASCII into a game played with
+// rules. The board's size replaces the size in rules. Header lines are
+// optional, blank lines and lines starting with # are ignored. Every 2x2
+// square of Emperor cells is one Emperor.
+func ParseASCII(text string, rules Rules) (State, error) {
+	s := State{
+		Level:         1,
+		Teleports:     rules.StartTeleports,
+		SafeTeleports: rules.StartSafeTeleports,
+		Screwdrivers:  rules.StartScrewdrivers,
+		LastStands:    rules.StartLastStands,
+	}
+	emperorHealth := max(rules.EmperorHealth, 1)
+	header := map[string]*int{
+		"Level":        &s.Level,
+		"Score":        &s.Score,
+		"Turns":        &s.Turns,
+		"Teleports":    &s.Teleports,
+		"Safe":         &s.SafeTeleports,
+		"Screwdrivers": &s.Screwdrivers,
+		"LastStands":   &s.LastStands,
+		"Emperor":      &emperorHealth,
+	}
+
+	var rows []string
+	var emperorCells []Position
+	players := 0
+	for i, line := range strings.Split(text, "\n") {
+		n := i + 1
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.Contains(line, ":"):
+			if len(rows) > 0 {
+				return s, fmt.Errorf("line %d: header after the board", n)
+			}
+			if err := parseHeader(line, header, &s.Seed); err != nil {
+				return s, fmt.Errorf("line %d: %w", n, err)
+			}
+			continue
+		}
+
+		if len(rows) > 0 && len(line) != len(rows[0]) {
+			return s, fmt.Errorf("line %d: row is %d cells wide, expected %d", n, len(line), len(rows[0]))
+		}
+		y := len(rows)
+		for x, c := range []byte(line) {
+			pos := Position{X: x, Y: y}
+			switch c {
+			case SymbolPlayer:
+				s.Player = pos
+				players++
+			case SymbolDalek:
+				s.Daleks = append(s.Daleks, NewDalek(DalekNormal, pos))
+			case SymbolFastDalek:
+				s.Daleks = append(s.Daleks, NewDalek(DalekFast, pos))
+			case SymbolArmoured:
+				s.Daleks = append(s.Daleks, NewDalek(DalekArmoured, pos))
+			case SymbolDamaged:
+				s.Daleks = append(s.Daleks, Dalek{Pos: pos, Kind: DalekArmoured})
+			case SymbolEmperor:
+				emperorCells = append(emperorCells, pos)
+			case SymbolScrap:
+				s.Scraps = append(s.Scraps, pos)
+			case SymbolEmpty:
+			default:
+				return s, fmt.Errorf("line %d, column %d: unknown symbol %q", n, x+1, c)
+			}
+		}
+		rows = append(rows, line)
+	}
+
+	emperors, err := parseEmperors(emperorCells, emperorHealth)
+	if err != nil {
+		return s, err
+	}
+	s.Daleks = append(emperors, s.Daleks...)
+
+	switch {
+	case len(rows) == 0:
+		return s, errors.New("no board found")
+	case players != 1:
+		return s, fmt.Errorf("board must have exactly one player (%c), found %d", SymbolPlayer, players)
+	case len(s.Daleks) == 0:
+		return s, fmt.Errorf("board has no daleks (%c)", SymbolDalek)
+	}
+
+	rules.Width = len(rows[0])
+	rules.Height = len(rows)
+	rules.Layout = nil
+	if rules.Width < minBoardSize || rules.Height < minBoardSize || rules.Width > maxBoardSize || rules.Height > maxBoardSize {
+		return s, fmt.Errorf("board is %dx%d, it must be between %d and %d cells each way",
+			rules.Width, rules.Height, minBoardSize, maxBoardSize)
+	}
+
+	s.Rules = rules
+	s.StartLevel = s.Level
+	s.RNG = NewRNG(s.Seed)
+	return s, s.Validate()
+}
+
+// parseEmperors groups Emperor cells, listed row by row, into 2x2 Emperors
+func parseEmperors(cells []Position, health int) ([]Dalek, error) {
+	left := make(map[Position]bool, len(cells))
+	for _, pos := range cells {
+		left[pos] = true
+	}
+
+	var emperors []Dalek
+	for _, pos := range cells {
+		if !left[pos] {
+			continue
+		}
+		emperor := Dalek{Pos: pos, Kind: DalekEmperor, Health: health}
+		for _, cell := range emperor.Cells() {
+			if !left[cell] {
+				return nil, fmt.Errorf("row %d, column %d: the Emperor (%c) must fill a 2x2 square", pos.Y+1, pos.X+1, SymbolEmperor)
+			}
+			left[cell] = false
+		}
+		emperors = append(emperors, emperor)
+	}
+	return emperors, nil
+}
+
+// parseHeader reads the "Key: value" pairs of a header line
+func parseHeader(line string, header map[string]*int, seed *uint64) error {
+	fields := strings.Fields(line)
+	if len(fields)%2 != 0 {
+		return errors.New("header must be made of \"Key: value\" pairs")
+	}
+
+	for i := 0; i < len(fields); i += 2 {
+		key, ok := strings.CutSuffix(fields[i], ":")
+		if !ok {
+			return fmt.Errorf("expected a \"Key:\", got %q", fields[i])
+		}
+		value := fields[i+1]
+
+		if key == "Seed" {
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("the seed must be a number, got %q", value)
+			}
+			*seed = v
+			continue
+		}
+
+		target, ok := header[key]
+		if !ok {
+			return fmt.Errorf("unknown header %q", key)
+		}
+		v, err := strconv.Atoi(value)
+		if err != nil || v < 0 {
+			return fmt.Errorf("the value of %s must be a number that is not negative, got %q", key, value)
+		}
+		*target = v
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestASCIIRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		board string
+	}{
+		{
+			name: "every symbol",
+			board: `Seed: 9  Level: 3  Score: 120  Turns: 4
+Teleports: 5  Safe: 1  Screwdrivers: 2  LastStands: 0  Emperor: 2
+.........
+.+....F..
+...EE....
+...EE..*.
+.........
+.A...a...
+....@....
+`,
+		},
+		{
+			name: "plain",
+			board: `Seed: 0  Level: 1  Score: 0  Turns: 0
+Teleports: 10  Safe: 3  Screwdrivers: 2  LastStands: 1
++....
+.....
+..@..
+.....
+....*
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseASCII(tt.board, DefaultRules())
+			if err != nil {
+				t.Fatalf("ParseASCII: %v", err)
+			}
+			if got := FormatASCII(s); got != tt.board {
+				t.Errorf("round trip gave\n%s\nwant\n%s", got, tt.board)
+			}
+		})
+	}
+}
+
+func TestASCIIRoundTripGames(t *testing.T) {
+	for _, p := range Presets() {
+		t.Run(p.Name, func(t *testing.T) {
+			s, _ := NewStateAt(p.Rules, 31, 10)
+			text := FormatASCII(s)
+			parsed, err := ParseASCII(text, p.Rules)
+			if err != nil {
+				t.Fatalf("ParseASCII: %v", err)
+			}
+			if got := FormatASCII(parsed); got != text {
+				t.Errorf("round trip gave\n%s\nwant\n%s", got, text)
+			}
+		})
+	}
+}
+
+func TestParseASCIIIgnoresComments(t *testing.T) {
+	s, err := ParseASCII(`
+		# A small board
+
+		+....
+		.....
+		..@..
+		.....
+		.....
+	`, DefaultRules())
+	if err != nil {
+		t.Fatalf("ParseASCII: %v", err)
+	}
+	if s.Rules.Width != 5 || s.Rules.Height != 5 || s.Level != 1 {
+		t.Errorf("board is %dx%d on level %d, want 5x5 on level 1", s.Rules.Width, s.Rules.Height, s.Level)
+	}
+}
+
+func TestParseASCIIErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		board string
+		want  string
+	}{
+		{"empty", "", "no board found"},
+		{"no player", "+....\n.....\n.....\n.....\n.....", "exactly one player"},
+		{"two players", "+...@\n.....\n.....\n.....\n....@", "exactly one player"},
+		{"no daleks", "....@\n.....\n.....\n.....\n.....", "no daleks"},
+		{"unknown symbol", "+...@\n..x..\n.....\n.....\n.....", "unknown symbol"},
+		{"ragged rows", "+...@\n....\n.....\n.....\n.....", "expected 5"},
+		{"too small", "+.@\n...\n...", "must be between"},
+		{"header after board", "+...@\n.....\nLevel: 2\n.....\n.....", "header after the board"},
+		{"broken Emperor", "+...@\n.E...\n.....\n.....\n.....", "Emperor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseASCII(tt.board, DefaultRules())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
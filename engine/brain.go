@@ -0,0 +1,312 @@
+package engine
+
+import (
+	"math"
+	"slices"
+)
+
+// Names of the built-in dalek brains, as used in rules files
+const (
+	BrainGreedy      = "greedy"      // One step straight at the player, as in the original game
+	BrainPathfinding = "pathfinding" // Shortest route to the player around scrap heaps
+	BrainFlanking    = "flanking"    // Spread out to cut off the cells the player could escape to
+	BrainCautious    = "cautious"    // Greedy, but sidesteps cells another dalek is moving onto
+)
+
+// flankRange is how close a flanking dalek gets before it goes straight for
+// the player
+const flankRange = 2
+
+// DalekBrain decides where daleks move. Plan returns the cell each of the
+// given daleks steps onto this turn, in the same order. Every cell must be
+// the dalek's own or one of its neighbours; anything else is replaced by a
+// greedy step.
+type DalekBrain interface {
+	Plan(board BoardView, daleks []int) []Position
+}
+
+var brains = map[string]DalekBrain{
+	BrainGreedy:      greedyBrain{},
+	BrainPathfinding: pathfindingBrain{},
+	BrainFlanking:    flankingBrain{},
+	BrainCautious:    cautiousBrain{},
+}
+
+// BrainNames lists the built-in brains, for help text and validation
+func BrainNames() []string {
+	return []string{BrainGreedy, BrainPathfinding, BrainFlanking, BrainCautious}
+}
+
+// LevelBrain switches the daleks to another brain from a level onwards
+type LevelBrain struct {
+	From  int    `json:"from"`
+	Brain string `json:"brain"`
+}
+
+// BrainFor returns the name of the brain daleks use on the given level
+func (r Rules) BrainFor(level int) string {
+	name := r.Brain
+	for _, lb := range r.LevelBrains {
+		if lb.From <= level {
+			name = lb.Brain
+		}
+	}
+	if name == "" {
+		return BrainGreedy
+	}
+	return name
+}
+
+// BoardView is the read-only view of the board brains plan from. It shows
+// the board as it was before any dalek moved this turn, and where the daleks
+// that have already planned are heading.
+type BoardView struct {
+	s    *State
+	next []Position // Planned cell of each dalek, its own until planned
+}
+
+// Width returns the board width in cells
+func (b BoardView) Width() int { return b.s.Rules.Width }
+
+// Height returns the board height in cells
+func (b BoardView) Height() int { return b.s.Rules.Height }
+
+// Player returns the player's cell
+func (b BoardView) Player() Position { return b.s.Player }
+
+// Daleks returns how many daleks are on the board
+func (b BoardView) Daleks() int { return len(b.s.Daleks) }
+
+// Dalek returns the cell of dalek i
+func (b BoardView) Dalek(i int) Position { return b.s.Daleks[i].Pos }
+
+// Heading returns the cells dalek i will cover after this step: where it
+// plans to go if it moves with another brain that has already planned,
+// otherwise where it stands
+func (b BoardView) Heading(i int) []Position {
+	dalek := b.s.Daleks[i]
+	if b.next != nil {
+		dalek.Pos = b.next[i]
+	}
+	return dalek.Cells()
+}
+
+// InBounds reports whether pos lies on the board
+func (b BoardView) InBounds(pos Position) bool { return b.s.InBounds(pos) }
+
+// ScrapHeaps returns how many scrap heaps are on the board
+func (b BoardView) ScrapHeaps() int { return len(b.s.Scraps) }
+
+// Scrap returns the cell of scrap heap i
+func (b BoardView) Scrap(i int) Position { return b.s.Scraps[i] }
+
+// HasScrap reports whether a scrap heap occupies pos
+func (b BoardView) HasScrap(pos Position) bool { return b.s.HasScrap(pos) }
+
+// Open reports whether pos is on the board and free of scrap
+func (b BoardView) Open(pos Position) bool { return b.InBounds(pos) && !b.HasScrap(pos) }
+
+// kingSteps are the eight directions a dalek or the player can step
+var kingSteps = []Position{
+	{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0},
+	{X: -1, Y: -1}, {X: 1, Y: -1}, {X: -1, Y: 1}, {X: 1, Y: 1},
+}
+
+// turnsApart returns how many steps separate two cells on an open board
+func turnsApart(a, b Position) int {
+	return max(abs(a.X-b.X), abs(a.Y-b.Y))
+}
+
+// greedyBrain is the classic rule: one sign step towards the player
+type greedyBrain struct{}
+
+func (greedyBrain) Plan(board BoardView, daleks []int) []Position {
+	next := make([]Position, len(daleks))
+	for k, i := range daleks {
+		next[k] = StepToward(board.Dalek(i), board.Player())
+	}
+	return next
+}
+
+// pathfindingBrain follows the shortest route to the player that does not
+// cross scrap. Daleks with no such route fall back to a greedy step.
+type pathfindingBrain struct{}
+
+func (pathfindingBrain) Plan(board BoardView, daleks []int) []Position {
+	dist := playerDistances(board, daleks)
+	at := func(pos Position) int {
+		if !board.InBounds(pos) || dist[pos.Y*board.Width()+pos.X] < 0 {
+			return math.MaxInt
+		}
+		return dist[pos.Y*board.Width()+pos.X]
+	}
+
+	next := make([]Position, len(daleks))
+	for k, i := range daleks {
+		pos := board.Dalek(i)
+		best := StepToward(pos, board.Player())
+		for _, step := range kingSteps {
+			cell := Position{X: pos.X + step.X, Y: pos.Y + step.Y}
+			if at(cell) < at(best) {
+				best = cell
+			}
+		}
+		next[k] = best
+	}
+	return next
+}
+
+// playerDistances returns the number of steps from cells to the player
+// without crossing scrap, indexed by y*width+x. The search stops once every
+// cell next to the given daleks is measured; -1 marks cells that were not
+// reached.
+func playerDistances(board BoardView, daleks []int) []int {
+	w, h := board.Width(), board.Height()
+	dist := make([]int, w*h)
+	for i := range dist {
+		dist[i] = -1
+	}
+
+	// Scrap is marked -2 so the search never enters it
+	for i := range board.ScrapHeaps() {
+		if pos := board.Scrap(i); board.InBounds(pos) {
+			dist[pos.Y*w+pos.X] = -2
+		}
+	}
+	waiting := make([]bool, w*h)
+	left := 0
+	for _, i := range daleks {
+		pos := board.Dalek(i)
+		if board.InBounds(pos) && !waiting[pos.Y*w+pos.X] {
+			waiting[pos.Y*w+pos.X] = true
+			left++
+		}
+	}
+
+	player := board.Player()
+	queue := []int{player.Y*w + player.X}
+	dist[queue[0]] = 0
+	furthest := math.MaxInt // Distance of the furthest dalek, once all are found
+	for head := 0; head < len(queue); head++ {
+		cell := queue[head]
+		d := dist[cell]
+		if d > furthest {
+			break
+		}
+		if waiting[cell] {
+			if left--; left == 0 {
+				furthest = d
+			}
+		}
+
+		x, y := cell%w, cell/w
+		for _, step := range kingSteps {
+			nx, ny := x+step.X, y+step.Y
+			if nx < 0 || nx >= w || ny < 0 || ny >= h || dist[ny*w+nx] != -1 {
+				continue
+			}
+			dist[ny*w+nx] = d + 1
+			queue = append(queue, ny*w+nx)
+		}
+	}
+	return dist
+}
+
+// flankingBrain sends the nearest dalek straight at the player and the
+// rest towards the cells the player could escape to, one dalek per cell.
+// Once close, every dalek goes for the player.
+type flankingBrain struct{}
+
+func (flankingBrain) Plan(board BoardView, daleks []int) []Position {
+	player := board.Player()
+	var escapes []Position
+	for _, step := range kingSteps {
+		if cell := (Position{X: player.X + step.X, Y: player.Y + step.Y}); board.Open(cell) {
+			escapes = append(escapes, cell)
+		}
+	}
+
+	// Nearest daleks pick first, ties in dalek order
+	order := make([]int, len(daleks))
+	for k := range order {
+		order[k] = k
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return Distance(board.Dalek(daleks[a]), player) - Distance(board.Dalek(daleks[b]), player)
+	})
+
+	claimed := make([]bool, len(escapes))
+	next := make([]Position, len(daleks))
+	for rank, k := range order {
+		pos := board.Dalek(daleks[k])
+		target := player
+		if rank > 0 && turnsApart(pos, player) > flankRange {
+			best := -1
+			for j, cell := range escapes {
+				if !claimed[j] && (best < 0 || Distance(pos, cell) < Distance(pos, escapes[best])) {
+					best = j
+				}
+			}
+			if best >= 0 {
+				claimed[best] = true
+				target = escapes[best]
+			}
+		}
+		next[k] = StepToward(pos, target)
+	}
+	return next
+}
+
+// cautiousBrain steps like the greedy brain, but a dalek whose step would
+// land on a cell another dalek is heading for takes another step that
+// still closes in, or holds still if there is none. It plans after every
+// other brain, so it sees where all the other daleks end up, including
+// those that hold still and the Emperor. It is no wiser about scrap than
+// the greedy brain.
+type cautiousBrain struct{}
+
+func (cautiousBrain) Plan(board BoardView, daleks []int) []Position {
+	player := board.Player()
+	taken := make(map[Position]bool, board.Daleks())
+	next := make([]Position, len(daleks))
+
+	// Cells the daleks outside this group end up on
+	ours := make(map[int]bool, len(daleks))
+	for _, i := range daleks {
+		ours[i] = true
+	}
+	for i := range board.Daleks() {
+		if !ours[i] {
+			for _, cell := range board.Heading(i) {
+				taken[cell] = true
+			}
+		}
+	}
+
+	for k, i := range daleks {
+		pos := board.Dalek(i)
+		next[k] = StepToward(pos, player)
+
+		if next[k] != player && taken[next[k]] {
+			// Other open steps that still close in, nearest the player first
+			var options []Position
+			for _, step := range kingSteps {
+				cell := Position{X: pos.X + step.X, Y: pos.Y + step.Y}
+				if turnsApart(cell, player) < turnsApart(pos, player) && board.Open(cell) && !taken[cell] {
+					options = append(options, cell)
+				}
+			}
+			slices.SortStableFunc(options, func(a, b Position) int {
+				return Distance(a, player) - Distance(b, player)
+			})
+			switch {
+			case len(options) > 0:
+				next[k] = options[0]
+			case !taken[pos]:
+				next[k] = pos
+			}
+		}
+		taken[next[k]] = true
+	}
+	return next
+}
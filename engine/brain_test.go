@@ -0,0 +1,135 @@
+package engine
+
+import "testing"
+
+func TestBrains(t *testing.T) {
+	// Two daleks heading for the same cell, and one behind a wall of scrap
+	converging := `
+		.+.+.
+		.....
+		.....
+		.....
+		..@..`
+	walled := `
+		.......
+		.*.....
+		+*...@.
+		.*.....
+		.......`
+
+	tests := []struct {
+		name       string
+		brain      string
+		board      string
+		wantDaleks int
+	}{
+		{"greedy crash", BrainGreedy, converging, 0},
+		{"cautious sidestep", BrainCautious, converging, 2},
+		{"greedy into scrap", BrainGreedy, walled, 0},
+		{"pathfinding around scrap", BrainPathfinding, walled, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultRules()
+			rules.Brain = tt.brain
+			rules.MaxLevel = 1
+			s := parseBoard(t, rules, tt.board)
+
+			next, _ := Step(s, Action{Kind: ActionWait})
+			if len(next.Daleks) != tt.wantDaleks {
+				t.Errorf("%d daleks left, want %d:\n%s", len(next.Daleks), tt.wantDaleks, FormatASCII(next))
+			}
+			for _, dalek := range next.Daleks {
+				if next.HasScrap(dalek.Pos) {
+					t.Errorf("dalek standing on scrap at %v", dalek.Pos)
+				}
+			}
+		})
+	}
+}
+
+func TestBrainsStepOneCell(t *testing.T) {
+	for _, name := range BrainNames() {
+		t.Run(name, func(t *testing.T) {
+			rules, _ := PresetRules("Nightmare")
+			rules.Brain = name
+			rules.Spawns = nil
+			rules.BossEvery = 0
+			s, _ := NewState(rules, 11)
+
+			for turn := 0; turn < 20 && s.Phase == PhasePlaying; turn++ {
+				next, events := Step(s, Action{Kind: ActionWait})
+				for _, e := range events {
+					if e.Kind != EventDaleksMoved {
+						continue
+					}
+					for _, move := range e.Moves {
+						if move.To != move.From && !IsAdjacent(move.From, move.To) {
+							t.Fatalf("turn %d: dalek moved from %v to %v", turn+1, move.From, move.To)
+						}
+					}
+				}
+				s = next
+			}
+		})
+	}
+}
+
+func TestKindBrains(t *testing.T) {
+	rules := DefaultRules()
+	rules.KindBrains = map[DalekKind]string{DalekFast: BrainPathfinding}
+	rules.LevelBrains = []LevelBrain{{From: 1, Brain: BrainCautious}}
+	s := parseBoard(t, rules, `
+		F+...
+		.....
+		.....
+		.....
+		..@..`)
+
+	if got := s.dalekBrain(s.Daleks[0]); got != BrainPathfinding {
+		t.Errorf("fast dalek uses %q, want %q", got, BrainPathfinding)
+	}
+	if got := s.dalekBrain(s.Daleks[1]); got != BrainCautious {
+		t.Errorf("normal dalek uses %q, want %q", got, BrainCautious)
+	}
+}
+
+func TestCautiousBrainAvoidsOtherDaleks(t *testing.T) {
+	tests := []struct {
+		name       string
+		kindBrains map[DalekKind]string
+		board      string
+	}{
+		// The fast dalek is greedy and heads for the same cell
+		{"dalek with another brain", map[DalekKind]string{DalekFast: BrainGreedy}, `
+			.+.F.
+			.....
+			.....
+			.....
+			..@..`},
+		// The normal dalek holds still on the fast dalek's second step
+		{"dalek holding still", map[DalekKind]string{DalekNormal: BrainGreedy}, `
+			.....
+			..F..
+			..+..
+			.....
+			.....
+			..@..`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultRules()
+			rules.Brain = BrainCautious
+			rules.KindBrains = tt.kindBrains
+			rules.MaxLevel = 1
+			s := parseBoard(t, rules, tt.board)
+
+			next, _ := Step(s, Action{Kind: ActionWait})
+			if len(next.Daleks) != 2 || len(next.Scraps) != 0 {
+				t.Errorf("daleks crashed:\n%s", FormatASCII(next))
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package engine
+
+// Handler is called with every event it subscribed to
+type Handler func(Event)
+
+// Bus delivers events to independent subscribers such as sound, visual
+// effects, statistics and logging, so none of them has to live in the rules
+type Bus struct {
+	handlers map[EventKind][]Handler
+	all      []Handler
+}
+
+// Subscribe registers a handler for a single kind of event
+func (b *Bus) Subscribe(kind EventKind, h Handler) {
+	if b.handlers == nil {
+		b.handlers = make(map[EventKind][]Handler)
+	}
+	b.handlers[kind] = append(b.handlers[kind], h)
+}
+
+// SubscribeAll registers a handler for every event
+func (b *Bus) SubscribeAll(h Handler) {
+	b.all = append(b.all, h)
+}
+
+// Publish delivers an event to its subscribers in the order they subscribed
+func (b *Bus) Publish(e Event) {
+	for _, h := range b.handlers[e.Kind] {
+		h(e)
+	}
+	for _, h := range b.all {
+		h(e)
+	}
+}
@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// DailyDate returns the UTC date of the daily challenge being played at t
+func DailyDate(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// DailySeed returns the seed of the daily challenge for a date written as
+// YYYY-MM-DD. It is computed locally, so every player gets the same board
+// without going online.
+func DailySeed(date string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte("godaleks daily " + date))
+
+	// Keep the seed short enough to read back and type in
+	return h.Sum64() % 1000000000
+}
+
+// DailyRules returns the fixed rules every daily challenge is played with
+func DailyRules() Rules {
+	rules := DefaultRules()
+	rules.Name = "Daily"
+	return rules
+}
@@ -0,0 +1,1107 @@
+// Package engine implements the Daleks rules without any rendering, input
+// or audio dependencies. A State is a plain value; Step applies one player
+// action to it and reports what happened as a list of Events.
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Position is a cell on the board
+type Position struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Phase is the outcome of the game so far
+type Phase int
+
+const (
+	PhasePlaying Phase = iota
+	PhaseGameOver
+	PhaseWin
+)
+
+// String returns a readable name for the phase
+func (p Phase) String() string {
+	switch p {
+	case PhasePlaying:
+		return "in progress"
+	case PhaseGameOver:
+		return "caught"
+	case PhaseWin:
+		return "won"
+	}
+	return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+// DalekKind is the type of a dalek
+type DalekKind int
+
+const (
+	DalekNormal   DalekKind = iota
+	DalekFast               // Takes two steps every turn, like the super robots of BSD robots
+	DalekArmoured           // Survives its first crash
+	DalekEmperor            // The 2x2 boss of boss levels
+)
+
+var dalekKindNames = map[DalekKind]string{
+	DalekNormal:   "normal",
+	DalekFast:     "fast",
+	DalekArmoured: "armoured",
+	DalekEmperor:  "emperor",
+}
+
+// String returns the kind's name, as used in rules files
+func (k DalekKind) String() string {
+	if name, ok := dalekKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("DalekKind(%d)", int(k))
+}
+
+// MarshalText writes the kind by name
+func (k DalekKind) MarshalText() ([]byte, error) {
+	if _, ok := dalekKindNames[k]; !ok {
+		return nil, fmt.Errorf("unknown dalek kind %d", int(k))
+	}
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText reads a kind written by name
+func (k *DalekKind) UnmarshalText(text []byte) error {
+	for kind, name := range dalekKindNames {
+		if name == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown dalek kind %q (choose normal, fast, armoured or emperor)", text)
+}
+
+// Steps returns how many cells a dalek of this kind moves every turn
+func (k DalekKind) Steps() int {
+	if k == DalekFast {
+		return 2
+	}
+	return 1
+}
+
+// Armour returns how many crashes a new dalek of this kind survives
+func (k DalekKind) Armour() int {
+	if k == DalekArmoured {
+		return 1
+	}
+	return 0
+}
+
+// Size returns how many cells wide and tall a dalek of this kind is
+func (k DalekKind) Size() int {
+	if k == DalekEmperor {
+		return 2
+	}
+	return 1
+}
+
+// Rests reports whether a dalek of this kind sits out the given turn of a
+// level. The Emperor only moves on even turns.
+func (k DalekKind) Rests(turn int) bool {
+	return k == DalekEmperor && turn%2 != 0
+}
+
+// Dalek is a single pursuer on the board
+type Dalek struct {
+	Pos    Position  `json:"pos"` // Top-left cell for daleks larger than one cell
+	Kind   DalekKind `json:"kind,omitempty"`
+	Health int       `json:"health,omitempty"` // Crashes it can still survive
+}
+
+// Cells returns every cell the dalek covers, starting with Pos
+func (d Dalek) Cells() []Position {
+	size := d.Kind.Size()
+	cells := make([]Position, 0, size*size)
+	for dy := range size {
+		for dx := range size {
+			cells = append(cells, Position{X: d.Pos.X + dx, Y: d.Pos.Y + dy})
+		}
+	}
+	return cells
+}
+
+// Covers reports whether pos is one of the dalek's cells
+func (d Dalek) Covers(pos Position) bool {
+	size := d.Kind.Size()
+	return pos.X >= d.Pos.X && pos.X < d.Pos.X+size && pos.Y >= d.Pos.Y && pos.Y < d.Pos.Y+size
+}
+
+// NewDalek returns a dalek of the given kind with its armour intact
+func NewDalek(kind DalekKind, pos Position) Dalek {
+	return Dalek{Pos: pos, Kind: kind, Health: kind.Armour()}
+}
+
+// State is a complete snapshot of a game
+type State struct {
+	Rules Rules `json:"rules"`
+	Pack  *Pack `json:"pack,omitempty"` // Level pack being played, if any
+	Phase Phase `json:"phase"`
+
+	Seed       uint64 `json:"seed"`       // Seed the game was created from
+	StartLevel int    `json:"startLevel"` // Level the game was started on
+	RNG        RNG    `json:"rng"`        // Generator used for teleport destinations
+
+	Player Position   `json:"player"`
+	Daleks []Dalek    `json:"daleks"`
+	Scraps []Position `json:"scraps"`
+
+	Level         int `json:"level"`
+	Turns         int `json:"turns"` // Turns taken on this level
+	Score         int `json:"score"`
+	Teleports     int `json:"teleports"`
+	SafeTeleports int `json:"safeTeleports"`
+	Screwdrivers  int `json:"screwdrivers"`
+	LastStands    int `json:"lastStands"`
+}
+
+// ActionKind identifies a player action
+type ActionKind int
+
+const (
+	ActionMove ActionKind = iota
+	ActionWait
+	ActionTeleport
+	ActionSafeTeleport
+	ActionScrewdriver
+	ActionLastStand
+	ActionWaitUntilSafe
+)
+
+// Action is a single player turn. DX and DY are only used by ActionMove.
+type Action struct {
+	Kind ActionKind `json:"kind"`
+	DX   int        `json:"dx,omitempty"`
+	DY   int        `json:"dy,omitempty"`
+}
+
+// MoveAction returns a move action in the given direction
+func MoveAction(dx, dy int) Action {
+	return Action{Kind: ActionMove, DX: dx, DY: dy}
+}
+
+// NewState creates a new game on level 1. Games created from the same rules
+// and seed are identical for the same sequence of actions.
+func NewState(rules Rules, seed uint64) (State, []Event) {
+	return NewStateAt(rules, seed, 1)
+}
+
+// NewStateAt creates a new game starting on the given level with the
+// starting inventory
+func NewStateAt(rules Rules, seed uint64, level int) (State, []Event) {
+	return newState(rules, nil, seed, level)
+}
+
+// NewPackState creates a new game that plays through a level pack from the
+// given level. Seeds only matter for levels with random placement.
+func NewPackState(pack *Pack, seed uint64, level int) (State, []Event) {
+	level = min(level, len(pack.Levels))
+	return newState(pack.LevelRules(level), pack, seed, level)
+}
+
+func newState(rules Rules, pack *Pack, seed uint64, level int) (State, []Event) {
+	if level < 1 {
+		level = 1
+	}
+
+	s := State{
+		Rules:         rules,
+		Pack:          pack,
+		Seed:          seed,
+		StartLevel:    level,
+		RNG:           NewRNG(seed),
+		Level:         level,
+		Teleports:     rules.StartTeleports,
+		SafeTeleports: rules.StartSafeTeleports,
+		Screwdrivers:  rules.StartScrewdrivers,
+		LastStands:    rules.StartLastStands,
+	}
+	var events []Event
+	s.startLevel(&events)
+	return s, events
+}
+
+// Clone returns a deep copy of the state
+func (s State) Clone() State {
+	s.Daleks = append([]Dalek(nil), s.Daleks...)
+	s.Scraps = append([]Position(nil), s.Scraps...)
+	return s
+}
+
+// Validate reports whether the state is internally consistent, for use on
+// states that were loaded from outside the engine
+func (s State) Validate() error {
+	if s.Rules.Width <= 0 || s.Rules.Height <= 0 {
+		return fmt.Errorf("invalid board size %dx%d", s.Rules.Width, s.Rules.Height)
+	}
+	if s.Phase < PhasePlaying || s.Phase > PhaseWin {
+		return fmt.Errorf("unknown phase %d", s.Phase)
+	}
+	if s.Level < 1 {
+		return fmt.Errorf("invalid level %d", s.Level)
+	}
+	if s.Pack != nil && s.Level > len(s.Pack.Levels) {
+		return fmt.Errorf("level %d is past the end of pack %q", s.Level, s.Pack.Name)
+	}
+	if s.Teleports < 0 || s.SafeTeleports < 0 || s.Screwdrivers < 0 || s.LastStands < 0 {
+		return errors.New("negative item count")
+	}
+	if !s.InBounds(s.Player) {
+		return fmt.Errorf("player at %d,%d is off the board", s.Player.X, s.Player.Y)
+	}
+	for _, dalek := range s.Daleks {
+		for _, cell := range dalek.Cells() {
+			if !s.InBounds(cell) {
+				return fmt.Errorf("dalek at %d,%d is off the board", dalek.Pos.X, dalek.Pos.Y)
+			}
+		}
+		if _, ok := dalekKindNames[dalek.Kind]; !ok {
+			return fmt.Errorf("dalek at %d,%d has unknown kind %d", dalek.Pos.X, dalek.Pos.Y, dalek.Kind)
+		}
+		if dalek.Health < 0 {
+			return fmt.Errorf("dalek at %d,%d has negative health", dalek.Pos.X, dalek.Pos.Y)
+		}
+	}
+	for _, scrap := range s.Scraps {
+		if !s.InBounds(scrap) {
+			return fmt.Errorf("scrap at %d,%d is off the board", scrap.X, scrap.Y)
+		}
+	}
+	return nil
+}
+
+// Step applies a player action and returns the resulting state together with
+// the events it produced. Invalid actions return the state unchanged and no events.
+func Step(s State, a Action) (State, []Event) {
+	if s.Phase != PhasePlaying {
+		return s, nil
+	}
+
+	next := s.Clone()
+	var events []Event
+	ok := false
+
+	switch a.Kind {
+	case ActionMove:
+		ok = next.movePlayer(a.DX, a.DY, &events)
+	case ActionWait:
+		ok = true
+		next.takeTurn(&events)
+	case ActionTeleport:
+		ok = next.teleport(false, &events)
+	case ActionSafeTeleport:
+		ok = next.teleport(true, &events)
+	case ActionScrewdriver:
+		ok = next.useScrewdriver(&events)
+	case ActionLastStand:
+		ok = next.lastStand(&events)
+	case ActionWaitUntilSafe:
+		ok = next.waitUntilSafe(&events)
+	}
+
+	if !ok {
+		return s, nil
+	}
+	next.checkTurnLimit(&events)
+	return next, events
+}
+
+// checkTurnLimit ends the game once the level's turns have run out
+func (s *State) checkTurnLimit(events *[]Event) {
+	if s.Phase != PhasePlaying || s.Rules.MaxTurns <= 0 || s.Turns < s.Rules.MaxTurns {
+		return
+	}
+	s.Phase = PhaseGameOver
+	*events = append(*events, Event{Kind: EventOutOfTurns, Pos: s.Player, Level: s.Level})
+}
+
+// maxScrapAttempts is how many random cells startLevel tries for scrap
+// heaps before it fills the rest of them in order
+const maxScrapAttempts = 100000
+
+// startLevel clears the board and places the player and daleks
+func (s *State) startLevel(events *[]Event) {
+	s.Scraps = nil
+	s.Turns = 0
+
+	// Pack levels bring their own board and items
+	if s.Pack != nil {
+		s.Rules = s.Pack.LevelRules(s.Level)
+		s.grant(s.Pack.Level(s.Level).Grant)
+	}
+
+	// Hand-placed boards skip random placement
+	if layout := s.Rules.Layout; layout != nil {
+		s.Player = layout.Player
+		s.Daleks = make([]Dalek, 0, len(layout.Daleks)+len(layout.FastDaleks)+len(layout.ArmouredDaleks))
+		for _, pos := range layout.Daleks {
+			s.Daleks = append(s.Daleks, NewDalek(DalekNormal, pos))
+		}
+		for _, pos := range layout.FastDaleks {
+			s.Daleks = append(s.Daleks, NewDalek(DalekFast, pos))
+		}
+		for _, pos := range layout.ArmouredDaleks {
+			s.Daleks = append(s.Daleks, NewDalek(DalekArmoured, pos))
+		}
+		s.Scraps = append(s.Scraps, layout.Scraps...)
+		*events = append(*events, Event{Kind: EventLevelStarted, Level: s.Level, Pos: s.Player})
+		return
+	}
+
+	rng := levelRNG(s.Seed, s.Level)
+
+	// Place player randomly
+	s.Player = s.randomPosition(&rng)
+
+	// Never ask for more daleks than can be placed
+	dalekCount := min(s.Rules.DalekCount(s.Level), s.Rules.SpawnCapacity())
+	s.Daleks = make([]Dalek, 0, dalekCount)
+
+	// Boss levels start with the Emperor, in place of one dalek. Its other
+	// three cells are taken from the room left for the rest.
+	if s.Rules.BossLevel(s.Level) && s.placeEmperor(&rng) {
+		dalekCount = min(dalekCount, s.Rules.SpawnCapacity()-3)
+	}
+
+	// Levels past the density limit close in around the player
+	s.placeRing(&rng, min(s.Rules.RingDaleks(s.Level), dalekCount), s.Rules.RingRadius(s.Level))
+
+	for len(s.Daleks) < dalekCount {
+		pos := s.randomPosition(&rng)
+
+		// Don't place dalek on player or too close
+		if Distance(pos, s.Player) > s.Rules.MinSpawnDistance && !s.PositionOccupied(pos) {
+			s.Daleks = append(s.Daleks, NewDalek(s.spawnKind(&rng), pos))
+		}
+	}
+
+	// Scatter scrap heaps over the cells left, never on the player
+	free := s.Rules.Width*s.Rules.Height - 1
+	for _, dalek := range s.Daleks {
+		free -= len(dalek.Cells())
+	}
+	scraps := min(s.Rules.ScrapHeaps, free)
+	for attempts := 0; len(s.Scraps) < scraps && attempts < maxScrapAttempts; attempts++ {
+		pos := s.randomPosition(&rng)
+		if pos != s.Player && !s.PositionOccupied(pos) {
+			s.Scraps = append(s.Scraps, pos)
+		}
+	}
+
+	// A crowded board may leave random picks missing the last free cells,
+	// so fill them in order
+	for y := 0; y < s.Rules.Height && len(s.Scraps) < scraps; y++ {
+		for x := 0; x < s.Rules.Width && len(s.Scraps) < scraps; x++ {
+			if pos := (Position{X: x, Y: y}); pos != s.Player && !s.PositionOccupied(pos) {
+				s.Scraps = append(s.Scraps, pos)
+			}
+		}
+	}
+
+	*events = append(*events, Event{Kind: EventLevelStarted, Level: s.Level, Pos: s.Player})
+}
+
+// grant adds items to the player's inventory
+func (s *State) grant(items Items) {
+	s.Teleports += items.Teleports
+	s.SafeTeleports += items.SafeTeleports
+	s.Screwdrivers += items.Screwdrivers
+	s.LastStands += items.LastStands
+}
+
+// placeEmperor puts the Emperor where none of its cells is close to the
+// player, and reports whether there was room for it
+func (s *State) placeEmperor(rng *RNG) bool {
+	emperor := Dalek{Kind: DalekEmperor, Health: s.Rules.EmperorHealth}
+	size := emperor.Kind.Size()
+
+	var spots []Position
+	for y := 0; y+size <= s.Rules.Height; y++ {
+		for x := 0; x+size <= s.Rules.Width; x++ {
+			emperor.Pos = Position{X: x, Y: y}
+			if !slices.ContainsFunc(emperor.Cells(), func(cell Position) bool {
+				return Distance(cell, s.Player) <= s.Rules.MinSpawnDistance
+			}) {
+				spots = append(spots, emperor.Pos)
+			}
+		}
+	}
+	if len(spots) == 0 {
+		return false
+	}
+
+	emperor.Pos = spots[rng.Intn(len(spots))]
+	s.Daleks = append(s.Daleks, emperor)
+	return true
+}
+
+// placeRing puts up to n daleks on random cells of the square ring radius
+// cells from the player
+func (s *State) placeRing(rng *RNG, n, radius int) {
+	if n <= 0 {
+		return
+	}
+
+	var cells []Position
+	for dx := -radius; dx <= radius; dx++ {
+		for dy := -radius; dy <= radius; dy++ {
+			if max(abs(dx), abs(dy)) != radius {
+				continue
+			}
+			pos := Position{X: s.Player.X + dx, Y: s.Player.Y + dy}
+			if s.InBounds(pos) && Distance(pos, s.Player) > s.Rules.MinSpawnDistance && !s.PositionOccupied(pos) {
+				cells = append(cells, pos)
+			}
+		}
+	}
+
+	// Shuffle so the gaps in the ring differ from level to level
+	for i := len(cells) - 1; i > 0; i-- {
+		j := rng.Intn(i + 1)
+		cells[i], cells[j] = cells[j], cells[i]
+	}
+	for _, pos := range cells[:min(n, len(cells))] {
+		s.Daleks = append(s.Daleks, NewDalek(s.spawnKind(rng), pos))
+	}
+}
+
+// spawnKind picks the kind of a newly placed dalek from the level's spawn
+// weights. Levels where every dalek is normal draw nothing from rng.
+func (s *State) spawnKind(rng *RNG) DalekKind {
+	total := 0
+	for _, spawn := range s.Rules.Spawns {
+		total += spawn.WeightAt(s.Level)
+	}
+	if total == 0 {
+		return DalekNormal
+	}
+
+	// Validate keeps the chances within 100, and whatever is left is normal
+	roll := rng.Intn(100)
+	for _, spawn := range s.Rules.Spawns {
+		if roll -= spawn.WeightAt(s.Level); roll < 0 {
+			return spawn.Kind
+		}
+	}
+	return DalekNormal
+}
+
+func (s *State) randomPosition(rng *RNG) Position {
+	return Position{
+		X: rng.Intn(s.Rules.Width),
+		Y: rng.Intn(s.Rules.Height),
+	}
+}
+
+// Distance returns the squared distance between two cells
+func Distance(a, b Position) int {
+	dx := a.X - b.X
+	dy := a.Y - b.Y
+	return dx*dx + dy*dy
+}
+
+// InBounds reports whether pos lies on the board
+func (s State) InBounds(pos Position) bool {
+	return pos.X >= 0 && pos.X < s.Rules.Width && pos.Y >= 0 && pos.Y < s.Rules.Height
+}
+
+// PositionOccupied reports whether a dalek or scrap heap occupies pos
+func (s State) PositionOccupied(pos Position) bool {
+	for _, dalek := range s.Daleks {
+		if dalek.Covers(pos) {
+			return true
+		}
+	}
+	return s.HasScrap(pos)
+}
+
+// HasScrap reports whether a scrap heap occupies pos
+func (s State) HasScrap(pos Position) bool {
+	for _, scrap := range s.Scraps {
+		if scrap == pos {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *State) movePlayer(dx, dy int, events *[]Event) bool {
+	// The player only ever steps to a neighbouring cell
+	if abs(dx) > 1 || abs(dy) > 1 {
+		return false
+	}
+
+	newPos := Position{
+		X: s.Player.X + dx,
+		Y: s.Player.Y + dy,
+	}
+
+	// Check bounds and scrap
+	if !s.InBounds(newPos) || s.HasScrap(newPos) {
+		return false
+	}
+
+	s.Player = newPos
+	s.takeTurn(events)
+	return true
+}
+
+func (s *State) teleport(safe bool, events *[]Event) bool {
+	if safe && s.SafeTeleports <= 0 {
+		return false
+	}
+	if !safe && s.Teleports <= 0 && !s.Rules.UnlimitedTeleports {
+		return false
+	}
+
+	oldPos := s.Player
+	var newPos Position
+	maxAttempts := 100
+
+	for i := 0; i < maxAttempts; i++ {
+		newPos = s.randomPosition(&s.RNG)
+
+		if s.PositionOccupied(newPos) {
+			continue
+		}
+		// Safe teleport - find position with no daleks nearby
+		if safe && !s.IsSafePosition(newPos) {
+			continue
+		}
+		break
+	}
+
+	if safe {
+		s.SafeTeleports--
+	} else if !s.Rules.UnlimitedTeleports {
+		s.Teleports--
+	}
+
+	s.Player = newPos
+	*events = append(*events, Event{Kind: EventPlayerTeleported, From: oldPos, Pos: newPos, Safe: safe})
+
+	s.takeTurn(events)
+	return true
+}
+
+// IsSafePosition reports whether no dalek can reach pos in one move
+func (s State) IsSafePosition(pos Position) bool {
+	for _, dalek := range s.Daleks {
+		for _, cell := range dalek.Cells() {
+			if Distance(pos, cell) <= s.Rules.SafeTeleportDistance {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *State) useScrewdriver(events *[]Event) bool {
+	if s.Screwdrivers <= 0 {
+		return false
+	}
+	s.Screwdrivers--
+
+	// Destroy all daleks adjacent to player (including diagonally). The
+	// Emperor is immune.
+	targets := make([]Position, 0)
+	remaining := make([]Dalek, 0, len(s.Daleks))
+	points := 0
+
+	for _, dalek := range s.Daleks {
+		if dalek.Kind != DalekEmperor && IsAdjacent(dalek.Pos, s.Player) {
+			targets = append(targets, dalek.Pos)
+			points += s.Rules.ScrewdriverPoints
+			// Add debris pile at dalek's position
+			s.Scraps = append(s.Scraps, dalek.Pos)
+		} else {
+			remaining = append(remaining, dalek)
+		}
+	}
+
+	s.Daleks = remaining
+	s.Score += points
+	*events = append(*events, Event{Kind: EventScrewdriverFired, Pos: s.Player, Targets: targets, Count: len(targets), Points: points})
+
+	s.takeTurn(events)
+	return true
+}
+
+// IsAdjacent reports whether a and b are one of the 8 neighbouring cells
+func IsAdjacent(a, b Position) bool {
+	dx := abs(a.X - b.X)
+	dy := abs(a.Y - b.Y)
+	return dx <= 1 && dy <= 1 && (dx != 0 || dy != 0)
+}
+
+// lastStand makes the player hold position while every dalek keeps coming
+// until they are all destroyed or the player is caught
+func (s *State) lastStand(events *[]Event) bool {
+	if s.LastStands <= 0 && !s.Rules.UnlimitedLastStands {
+		return false
+	}
+	if !s.Rules.UnlimitedLastStands {
+		s.LastStands--
+	}
+
+	*events = append(*events, Event{Kind: EventLastStandStarted, Pos: s.Player})
+
+	// Every round brings each greedy dalek one cell closer, so the board
+	// resolves within the longest side of the grid. Daleks with a cautious
+	// brain may hold back, and whatever is left when the rounds run out
+	// survives.
+	maxRounds := s.Rules.Width + s.Rules.Height
+	for round := 0; round < maxRounds && s.Phase == PhasePlaying; round++ {
+		s.Turns++
+		if s.advanceDaleks(events) {
+			break
+		}
+
+		// Bonus for surviving Last Stand
+		if len(s.Daleks) == 0 {
+			bonus := s.Rules.LastStandBonus
+			s.Score += bonus
+			*events = append(*events, Event{Kind: EventLastStandEnded, Pos: s.Player, Points: bonus})
+			s.checkLevelComplete(events)
+			return true
+		}
+	}
+
+	if s.Phase == PhasePlaying {
+		*events = append(*events, Event{Kind: EventLastStandEnded, Pos: s.Player})
+	}
+	return true
+}
+
+// DalekAdjacent reports whether any dalek is next to the player
+func (s State) DalekAdjacent() bool {
+	for _, dalek := range s.Daleks {
+		for _, cell := range dalek.Cells() {
+			if IsAdjacent(cell, s.Player) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// waitUntilSafe is the robots 'w' command: the player keeps waiting until
+// a dalek is next to them or the level ends. Daleks destroyed meanwhile
+// earn the wait bonus. It is refused while a dalek is already adjacent.
+func (s *State) waitUntilSafe(events *[]Event) bool {
+	if s.DalekAdjacent() {
+		return false
+	}
+
+	*events = append(*events, Event{Kind: EventWaitStarted, Pos: s.Player})
+	start := len(*events)
+	level := s.Level
+
+	// Every turn brings each greedy dalek one cell closer, so one of them
+	// is adjacent within the longest side of the grid. Other brains may
+	// take longer, and the wait ends when the turns run out.
+	maxTurns := max(s.Rules.Width, s.Rules.Height)
+	for turn := 0; turn < maxTurns; turn++ {
+		s.takeTurn(events)
+		if s.Phase != PhasePlaying || s.Level != level || s.DalekAdjacent() {
+			break
+		}
+	}
+
+	destroyed := 0
+	for _, e := range (*events)[start:] {
+		if e.Kind == EventDalekHitScrap || e.Kind == EventDaleksCollided {
+			destroyed += e.Count
+		}
+	}
+	bonus := destroyed * s.Rules.WaitBonus
+	s.Score += bonus
+	*events = append(*events, Event{Kind: EventWaitEnded, Pos: s.Player, Count: destroyed, Points: bonus})
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// StepToward returns the cell one greedy step from 'from' towards 'target'
+func StepToward(from, target Position) Position {
+	dx := 0
+	dy := 0
+
+	if from.X < target.X {
+		dx = 1
+	} else if from.X > target.X {
+		dx = -1
+	}
+
+	if from.Y < target.Y {
+		dy = 1
+	} else if from.Y > target.Y {
+		dy = -1
+	}
+
+	return Position{X: from.X + dx, Y: from.Y + dy}
+}
+
+// takeTurn moves the daleks after a player action and resolves collisions
+func (s *State) takeTurn(events *[]Event) {
+	s.Turns++
+	if s.advanceDaleks(events) {
+		return
+	}
+	s.summonReinforcements(events)
+	s.checkLevelComplete(events)
+}
+
+// advanceDaleks moves the daleks for one turn and reports whether the
+// player was caught. Every step is resolved before the next, so a fast
+// dalek can crash or catch the player half way through its move.
+func (s *State) advanceDaleks(events *[]Event) bool {
+	for step := 0; step == 0 || s.stepsLeft(step); step++ {
+		moves := s.moveDaleks(step, events)
+		if s.playerCaught(events) {
+			return true
+		}
+
+		// Armoured daleks can bounce back onto the player
+		s.resolveCollisions(moves, events)
+		if s.playerCaught(events) {
+			return true
+		}
+	}
+	return false
+}
+
+// stepsLeft reports whether any dalek still moves on the given step of the
+// turn, counting from 0
+func (s *State) stepsLeft(step int) bool {
+	for _, dalek := range s.Daleks {
+		if s.moves(dalek, step) {
+			return true
+		}
+	}
+	return false
+}
+
+// moves reports whether a dalek moves on the given step of this turn
+func (s *State) moves(dalek Dalek, step int) bool {
+	return dalek.Kind.Steps() > step && !dalek.Kind.Rests(s.Turns)
+}
+
+// moveDaleks takes one step of the turn: every dalek that still moves on
+// this step goes where its brain plans, the rest hold still. Brains all plan
+// from the board before anyone moves. It returns the moves, in dalek order.
+func (s *State) moveDaleks(step int, events *[]Event) []Move {
+	next := make([]Position, len(s.Daleks))
+	board := BoardView{s: s, next: next}
+
+	// Daleks that share a brain plan together, so they can coordinate
+	var names []string
+	groups := make(map[string][]int)
+	for i, dalek := range s.Daleks {
+		next[i] = dalek.Pos
+		if !s.moves(dalek, step) {
+			continue
+		}
+		// The Emperor is too large for the brains and makes its own way
+		if dalek.Kind == DalekEmperor {
+			next[i] = s.emperorStep(dalek)
+			continue
+		}
+		name := s.dalekBrain(dalek)
+		if groups[name] == nil {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], i)
+	}
+	// The cautious brain steers around everyone else's plans, so it goes last
+	if i := slices.Index(names, BrainCautious); i >= 0 {
+		names = append(slices.Delete(names, i, i+1), BrainCautious)
+	}
+	for _, name := range names {
+		brain, ok := brains[name]
+		if !ok {
+			brain = greedyBrain{}
+		}
+		group := groups[name]
+		for k, pos := range brain.Plan(board, group) {
+			next[group[k]] = pos
+		}
+	}
+
+	moves := make([]Move, len(s.Daleks))
+	for i := range s.Daleks {
+		dalek := &s.Daleks[i]
+		newPos := next[i]
+		if newPos != dalek.Pos && !IsAdjacent(newPos, dalek.Pos) {
+			newPos = StepToward(dalek.Pos, s.Player)
+		}
+		moves[i] = Move{From: dalek.Pos, To: newPos, Kind: dalek.Kind, Health: dalek.Health}
+		dalek.Pos = newPos
+	}
+
+	*events = append(*events, Event{Kind: EventDaleksMoved, Moves: moves})
+	return moves
+}
+
+// emperorStep returns where the Emperor moves: one step that brings it
+// closer to the player while keeping every cell on the board and off scrap.
+// It tries a straight step when the diagonal is blocked, and holds still
+// when both are.
+func (s *State) emperorStep(emperor Dalek) Position {
+	size := emperor.Kind.Size()
+	nearest := Position{
+		X: min(max(s.Player.X, emperor.Pos.X), emperor.Pos.X+size-1),
+		Y: min(max(s.Player.Y, emperor.Pos.Y), emperor.Pos.Y+size-1),
+	}
+	step := StepToward(nearest, s.Player)
+	dx, dy := step.X-nearest.X, step.Y-nearest.Y
+
+	for _, d := range []Position{{X: dx, Y: dy}, {X: dx}, {Y: dy}} {
+		if d == (Position{}) {
+			continue
+		}
+		moved := emperor
+		moved.Pos = Position{X: emperor.Pos.X + d.X, Y: emperor.Pos.Y + d.Y}
+		if !slices.ContainsFunc(moved.Cells(), func(cell Position) bool {
+			return !s.InBounds(cell) || s.HasScrap(cell)
+		}) {
+			return moved.Pos
+		}
+	}
+	return emperor.Pos
+}
+
+// summonReinforcements lets a living Emperor call in daleks on the free
+// cells around it every SummonEvery turns. None land next to the player, or
+// behind the Emperor where their first step would run into it.
+func (s *State) summonReinforcements(events *[]Event) {
+	if s.Rules.SummonEvery <= 0 || s.Turns%s.Rules.SummonEvery != 0 {
+		return
+	}
+
+	for _, emperor := range s.Daleks {
+		if emperor.Kind != DalekEmperor {
+			continue
+		}
+
+		var cells []Position
+		size := emperor.Kind.Size()
+		for y := emperor.Pos.Y - 1; y <= emperor.Pos.Y+size; y++ {
+			for x := emperor.Pos.X - 1; x <= emperor.Pos.X+size; x++ {
+				pos := Position{X: x, Y: y}
+				free := s.InBounds(pos) && !s.PositionOccupied(pos) && pos != s.Player && !IsAdjacent(pos, s.Player)
+				if free && !emperor.Covers(StepToward(pos, s.Player)) {
+					cells = append(cells, pos)
+				}
+			}
+		}
+		for i := len(cells) - 1; i > 0; i-- {
+			j := s.RNG.Intn(i + 1)
+			cells[i], cells[j] = cells[j], cells[i]
+		}
+
+		summoned := cells[:min(s.Rules.Summons, len(cells))]
+		if len(summoned) == 0 {
+			continue
+		}
+		for _, pos := range summoned {
+			s.Daleks = append(s.Daleks, NewDalek(DalekNormal, pos))
+		}
+		*events = append(*events, Event{Kind: EventEmperorSummoned, Pos: emperor.Pos, Targets: summoned})
+	}
+}
+
+// dalekBrain returns the name of the brain a dalek moves with: its kind's
+// brain if the rules give one, or else the level's
+func (s *State) dalekBrain(dalek Dalek) string {
+	if name, ok := s.Rules.KindBrains[dalek.Kind]; ok {
+		return name
+	}
+	return s.Rules.BrainFor(s.Level)
+}
+
+// playerCaught ends the game if any dalek covers the player's cell
+func (s *State) playerCaught(events *[]Event) bool {
+	for _, dalek := range s.Daleks {
+		if dalek.Covers(s.Player) {
+			s.Phase = PhaseGameOver
+			*events = append(*events, Event{Kind: EventPlayerCaught, Pos: s.Player})
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCollisions turns daleks that hit scrap or each other into scrap.
+// A dalek with armour left loses it instead and bounces back to the cell
+// it came from, where it may crash again. One that was standing still takes
+// the hit where it stands, and no scrap is left under it. Daleks that run
+// into the Emperor, or that it runs over, are destroyed without leaving
+// scrap, and each one wears it down; it becomes scrap once worn out.
+func (s *State) resolveCollisions(moves []Move, events *[]Event) {
+	from := make([]Position, len(s.Daleks))
+	for i, move := range moves {
+		from[i] = move.From
+	}
+
+	for {
+		counts := make(map[Position]int)
+		under := make(map[Position]bool) // Cells covered by the Emperor
+		for _, dalek := range s.Daleks {
+			for _, cell := range dalek.Cells() {
+				if !s.HasScrap(cell) {
+					counts[cell]++
+				}
+				under[cell] = under[cell] || dalek.Kind == DalekEmperor
+			}
+		}
+
+		kept := make([]Dalek, 0, len(s.Daleks))
+		var crashedAt []Position // Where each kept dalek was before bouncing
+		var sites []Position     // Dalek crashes, in dalek order
+		wrecks := make(map[Position]int)
+		held := make(map[Position]bool)
+		var fallen []Position // Cells of a destroyed Emperor
+		damaged, bounced := false, false
+
+		for i, dalek := range s.Daleks {
+			pos := dalek.Pos
+			onScrap := s.HasScrap(pos)
+			switch {
+			case dalek.Kind == DalekEmperor:
+				hits := 0
+				for _, cell := range dalek.Cells() {
+					hits += max(counts[cell]-1, 0)
+				}
+				if hits == 0 {
+					kept = append(kept, dalek)
+					crashedAt = append(crashedAt, pos)
+					break
+				}
+
+				dalek.Health -= hits
+				if dalek.Health > 0 {
+					*events = append(*events, Event{Kind: EventEmperorHit, Pos: pos})
+					kept = append(kept, dalek)
+					crashedAt = append(crashedAt, pos)
+					break
+				}
+				points := s.Rules.EmperorPoints
+				s.Score += points
+				fallen = append(fallen, dalek.Cells()...)
+				*events = append(*events, Event{Kind: EventEmperorDestroyed, Pos: pos, Targets: dalek.Cells(), Points: points})
+
+			case !onScrap && counts[pos] < 2:
+				kept = append(kept, dalek)
+				crashedAt = append(crashedAt, pos)
+
+			case dalek.Health > 0 && (from[i] != pos || !under[pos]):
+				dalek.Health--
+				damaged = true
+				points := s.Rules.ArmourPoints
+				s.Score += points
+				*events = append(*events, Event{Kind: EventDalekDamaged, Pos: pos, From: from[i], Points: points})
+
+				if from[i] != pos {
+					dalek.Pos = from[i]
+					bounced = true
+				} else if !onScrap {
+					held[pos] = true
+				}
+				kept = append(kept, dalek)
+				crashedAt = append(crashedAt, pos)
+
+			case onScrap:
+				points := s.Rules.CrashPoints
+				s.Score += points
+				*events = append(*events, Event{Kind: EventDalekHitScrap, Pos: pos, Count: 1, Points: points})
+
+			default:
+				if wrecks[pos] == 0 {
+					sites = append(sites, pos)
+				}
+				wrecks[pos]++
+			}
+		}
+
+		// Report each crash site once, with points for every dalek destroyed
+		// in it. Crashes into the Emperor leave no scrap of their own.
+		for _, pos := range sites {
+			n := wrecks[pos]
+			points := s.Rules.CrashPoints * n
+			s.Score += points
+			held[pos] = held[pos] || under[pos]
+			if !held[pos] {
+				s.Scraps = append(s.Scraps, pos)
+			}
+			*events = append(*events, Event{Kind: EventDaleksCollided, Pos: pos, Count: n, Points: points, Held: held[pos]})
+		}
+		for _, cell := range fallen {
+			if !s.HasScrap(cell) {
+				s.Scraps = append(s.Scraps, cell)
+			}
+		}
+		s.Daleks = kept
+
+		if !damaged {
+			return
+		}
+
+		// Show the damage, and the bounce back off the wreck
+		bounces := make([]Move, len(kept))
+		for i, dalek := range kept {
+			bounces[i] = Move{From: crashedAt[i], To: dalek.Pos, Kind: dalek.Kind, Health: dalek.Health}
+		}
+		*events = append(*events, Event{Kind: EventDaleksMoved, Moves: bounces})
+		if !bounced {
+			return
+		}
+
+		// Bounced daleks may have landed on another crash
+		from = from[:0]
+		for _, dalek := range kept {
+			from = append(from, dalek.Pos)
+		}
+	}
+}
+
+// checkLevelComplete advances to the next level once every dalek is gone
+func (s *State) checkLevelComplete(events *[]Event) {
+	if s.Phase != PhasePlaying || len(s.Daleks) > 0 {
+		return
+	}
+
+	bonus := s.Level * s.Rules.LevelBonus
+	s.Score += bonus
+	*events = append(*events, Event{Kind: EventLevelCleared, Level: s.Level, Count: s.Turns, Points: bonus})
+
+	s.Level++
+	s.Teleports += s.Rules.taper(s.Rules.TeleportRefill, s.Level)
+	s.SafeTeleports += s.Rules.taper(s.Rules.SafeTeleportRefill, s.Level)
+	s.Screwdrivers = max(s.Screwdrivers+s.Rules.taper(s.Rules.ScrewdriverRefill, s.Level), s.Rules.ScrewdriverRecharge)
+	s.LastStands = s.Rules.LastStandsPerLevel
+	if s.Rules.LastStandBonusEvery > 0 && s.Level%s.Rules.LastStandBonusEvery == 0 {
+		s.LastStands++
+	}
+
+	if s.Rules.MaxLevel > 0 && s.Level > s.Rules.MaxLevel {
+		s.Phase = PhaseWin
+		*events = append(*events, Event{Kind: EventGameWon, Level: s.Rules.MaxLevel})
+		return
+	}
+	s.startLevel(events)
+}
@@ -0,0 +1,788 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// parseBoard reads a text board into a game played with rules
+func parseBoard(t *testing.T, rules Rules, text string) State {
+	t.Helper()
+	s, err := ParseASCII(text, rules)
+	if err != nil {
+		t.Fatalf("ParseASCII: %v", err)
+	}
+	return s
+}
+
+// hasEvent reports whether any event is of the given kind
+func hasEvent(events []Event, kind EventKind) bool {
+	return slices.ContainsFunc(events, func(e Event) bool { return e.Kind == kind })
+}
+
+// findEvent returns the first event of the given kind
+func findEvent(t *testing.T, events []Event, kind EventKind) Event {
+	t.Helper()
+	for _, e := range events {
+		if e.Kind == kind {
+			return e
+		}
+	}
+	t.Fatalf("no event %d in %v", kind, events)
+	return Event{}
+}
+
+// dalekPositions lists where every dalek stands, in dalek order
+func dalekPositions(s State) []Position {
+	positions := make([]Position, len(s.Daleks))
+	for i, dalek := range s.Daleks {
+		positions[i] = dalek.Pos
+	}
+	return positions
+}
+
+func TestStep(t *testing.T) {
+	tests := []struct {
+		name       string
+		board      string
+		action     Action
+		wantPlayer Position
+		wantDaleks []Position
+		wantScraps []Position
+		wantPhase  Phase
+		wantScore  int
+	}{
+		{
+			name: "move",
+			board: `
+				.......
+				+......
+				.......
+				...@...
+				.......
+				.......
+				......+`,
+			action:     MoveAction(1, 0),
+			wantPlayer: Position{X: 4, Y: 3},
+			wantDaleks: []Position{{X: 1, Y: 2}, {X: 5, Y: 5}},
+		},
+		{
+			name: "wait",
+			board: `
+				.......
+				+......
+				.......
+				...@...
+				.......
+				.......
+				......+`,
+			action:     Action{Kind: ActionWait},
+			wantPlayer: Position{X: 3, Y: 3},
+			wantDaleks: []Position{{X: 1, Y: 2}, {X: 5, Y: 5}},
+		},
+		{
+			name: "daleks collide",
+			board: `
+				.+.+.
+				.....
+				.....
+				.....
+				+.@..`,
+			action:     Action{Kind: ActionWait},
+			wantPlayer: Position{X: 2, Y: 4},
+			wantDaleks: []Position{{X: 1, Y: 4}},
+			wantScraps: []Position{{X: 2, Y: 1}},
+			wantScore:  4,
+		},
+		{
+			name: "dalek hits scrap",
+			board: `
+				..+..
+				..*..
+				.....
+				.....
+				+.@..`,
+			action:     Action{Kind: ActionWait},
+			wantPlayer: Position{X: 2, Y: 4},
+			wantDaleks: []Position{{X: 1, Y: 4}},
+			wantScraps: []Position{{X: 2, Y: 1}},
+			wantScore:  2,
+		},
+		{
+			name: "player caught",
+			board: `
+				.....
+				.....
+				..+..
+				..@..
+				.....`,
+			action:     Action{Kind: ActionWait},
+			wantPlayer: Position{X: 2, Y: 3},
+			wantDaleks: []Position{{X: 2, Y: 3}},
+			wantPhase:  PhaseGameOver,
+		},
+		{
+			name: "screwdriver",
+			board: `
+				+....
+				.....
+				.+@..
+				.....
+				.....`,
+			action:     Action{Kind: ActionScrewdriver},
+			wantPlayer: Position{X: 2, Y: 2},
+			wantDaleks: []Position{{X: 1, Y: 1}},
+			wantScraps: []Position{{X: 1, Y: 2}},
+			wantScore:  5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseBoard(t, DefaultRules(), tt.board)
+			next, events := Step(s, tt.action)
+			if events == nil {
+				t.Fatal("action was refused")
+			}
+			if next.Player != tt.wantPlayer {
+				t.Errorf("player at %v, want %v", next.Player, tt.wantPlayer)
+			}
+			if got := dalekPositions(next); !slices.Equal(got, tt.wantDaleks) {
+				t.Errorf("daleks at %v, want %v", got, tt.wantDaleks)
+			}
+			if !slices.Equal(next.Scraps, tt.wantScraps) {
+				t.Errorf("scrap at %v, want %v", next.Scraps, tt.wantScraps)
+			}
+			if next.Phase != tt.wantPhase {
+				t.Errorf("phase %d, want %d", next.Phase, tt.wantPhase)
+			}
+			if next.Score != tt.wantScore {
+				t.Errorf("score %d, want %d", next.Score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestStepRefused(t *testing.T) {
+	board := `
+		Teleports: 0  Safe: 0  Screwdrivers: 0  LastStands: 0
+		@*...
+		.....
+		.....
+		.....
+		....+`
+
+	tests := []struct {
+		name   string
+		action Action
+	}{
+		{"off the board", MoveAction(-1, 0)},
+		{"onto scrap", MoveAction(1, 0)},
+		{"two cells", MoveAction(0, 2)},
+		{"far away", MoveAction(3, 4)},
+		{"no teleports", Action{Kind: ActionTeleport}},
+		{"no safe teleports", Action{Kind: ActionSafeTeleport}},
+		{"no screwdrivers", Action{Kind: ActionScrewdriver}},
+		{"no last stands", Action{Kind: ActionLastStand}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseBoard(t, DefaultRules(), board)
+			next, events := Step(s, tt.action)
+			if events != nil {
+				t.Errorf("action was allowed, events %v", events)
+			}
+			if !reflect.DeepEqual(next, s) {
+				t.Errorf("refused action changed the state to %+v", next)
+			}
+		})
+	}
+}
+
+func TestStepAfterGameOver(t *testing.T) {
+	s := parseBoard(t, DefaultRules(), `
+		.....
+		.....
+		..+..
+		..@..
+		.....`)
+	s, _ = Step(s, Action{Kind: ActionWait})
+	if s.Phase != PhaseGameOver {
+		t.Fatalf("phase %d, want %d", s.Phase, PhaseGameOver)
+	}
+	if _, events := Step(s, MoveAction(1, 0)); events != nil {
+		t.Errorf("move after the game ended was allowed")
+	}
+}
+
+func TestWaitUntilSafe(t *testing.T) {
+	rules := DefaultRules()
+	rules.WaitBonus = 1
+	s := parseBoard(t, rules, `
+		+.....+
+		.......
+		.......
+		.......
+		.......
+		.......
+		...@..+`)
+
+	next, events := Step(s, Action{Kind: ActionWaitUntilSafe})
+	if events == nil {
+		t.Fatal("wait until safe was refused")
+	}
+	if !next.DalekAdjacent() {
+		t.Errorf("wait ended with no dalek next to the player")
+	}
+	if ended := findEvent(t, events, EventWaitEnded); ended.Points != ended.Count*rules.WaitBonus {
+		t.Errorf("wait bonus %d for %d daleks, want %d each", ended.Points, ended.Count, rules.WaitBonus)
+	}
+
+	// Waiting is refused while a dalek is already adjacent
+	if _, events := Step(next, Action{Kind: ActionWaitUntilSafe}); events != nil {
+		t.Errorf("wait until safe was allowed with a dalek adjacent")
+	}
+}
+
+func TestLevelProgression(t *testing.T) {
+	// Two daleks crash into each other and clear the level
+	board := `
+		Level: %d
+		.+.+.
+		.....
+		.....
+		.....
+		..@..`
+
+	tests := []struct {
+		name           string
+		level          int
+		maxLevel       int
+		wantLevel      int
+		wantPhase      Phase
+		wantScore      int
+		wantLastStands int
+	}{
+		{"next level", 1, 10, 2, PhasePlaying, 2*2 + 10, 1},
+		{"last stand bonus level", 4, 10, 5, PhasePlaying, 2*2 + 40, 2},
+		{"final level", 10, 10, 11, PhaseWin, 2*2 + 100, 1},
+		{"endless", 10, 0, 11, PhasePlaying, 2*2 + 100, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultRules()
+			rules.MaxLevel = tt.maxLevel
+			rules.DaleksPerLevel = 0
+			s := parseBoard(t, rules, fmt.Sprintf(board, tt.level))
+
+			next, events := Step(s, Action{Kind: ActionWait})
+			cleared := findEvent(t, events, EventLevelCleared)
+			if cleared.Level != tt.level || cleared.Points != tt.level*rules.LevelBonus {
+				t.Errorf("cleared level %d for %d points, want level %d for %d",
+					cleared.Level, cleared.Points, tt.level, tt.level*rules.LevelBonus)
+			}
+			if next.Level != tt.wantLevel {
+				t.Errorf("level %d, want %d", next.Level, tt.wantLevel)
+			}
+			if next.Phase != tt.wantPhase {
+				t.Errorf("phase %d, want %d", next.Phase, tt.wantPhase)
+			}
+			if next.Score != tt.wantScore {
+				t.Errorf("score %d, want %d", next.Score, tt.wantScore)
+			}
+			if next.LastStands != tt.wantLastStands {
+				t.Errorf("%d last stands, want %d", next.LastStands, tt.wantLastStands)
+			}
+			if next.Teleports != s.Teleports+rules.TeleportRefill {
+				t.Errorf("%d teleports, want %d", next.Teleports, s.Teleports+rules.TeleportRefill)
+			}
+
+			started := hasEvent(events, EventLevelStarted)
+			if won := tt.wantPhase == PhaseWin; started == won {
+				t.Errorf("level started %v after winning %v", started, won)
+			}
+			if started && (next.Turns != 0 || len(next.Daleks) != rules.BaseDaleks) {
+				t.Errorf("new level has %d turns and %d daleks, want 0 turns and %d daleks",
+					next.Turns, len(next.Daleks), rules.BaseDaleks)
+			}
+		})
+	}
+}
+
+func TestLastStand(t *testing.T) {
+	tests := []struct {
+		name      string
+		board     string
+		wantPhase Phase
+		wantBonus bool
+	}{
+		{
+			name: "every dalek destroyed",
+			board: `
+				+...+
+				.....
+				.....
+				.....
+				..@..`,
+			wantPhase: PhaseWin,
+			wantBonus: true,
+		},
+		{
+			name: "caught",
+			board: `
+				+....
+				.....
+				.....
+				.....
+				..@..`,
+			wantPhase: PhaseGameOver,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultRules()
+			rules.MaxLevel = 1
+			s := parseBoard(t, rules, tt.board)
+
+			next, events := Step(s, Action{Kind: ActionLastStand})
+			if events == nil {
+				t.Fatal("last stand was refused")
+			}
+			if next.Phase != tt.wantPhase {
+				t.Errorf("phase %d, want %d", next.Phase, tt.wantPhase)
+			}
+			if next.LastStands != s.LastStands-1 && tt.wantPhase != PhaseWin {
+				t.Errorf("%d last stands left, want %d", next.LastStands, s.LastStands-1)
+			}
+
+			bonus := 0
+			for _, e := range events {
+				if e.Kind == EventLastStandEnded {
+					bonus = e.Points
+				}
+			}
+			if got := bonus == rules.LastStandBonus; got != tt.wantBonus {
+				t.Errorf("last stand bonus %d, want bonus %v", bonus, tt.wantBonus)
+			}
+		})
+	}
+}
+
+func TestTurnLimit(t *testing.T) {
+	rules := DefaultRules()
+	rules.MaxTurns = 1
+	s := parseBoard(t, rules, `
+		+....
+		.....
+		.....
+		.....
+		....@`)
+
+	next, events := Step(s, Action{Kind: ActionWait})
+	if next.Phase != PhaseGameOver || !hasEvent(events, EventOutOfTurns) {
+		t.Errorf("phase %s after the last turn, events %v", next.Phase, events)
+	}
+}
+
+func TestTeleport(t *testing.T) {
+	tests := []struct {
+		name   string
+		action Action
+		safe   bool
+	}{
+		{"teleport", Action{Kind: ActionTeleport}, false},
+		{"safe teleport", Action{Kind: ActionSafeTeleport}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := NewState(DefaultRules(), 7)
+			next, events := Step(s, tt.action)
+			if events == nil {
+				t.Fatal("teleport was refused")
+			}
+
+			e := findEvent(t, events, EventPlayerTeleported)
+			if e.Safe != tt.safe || e.From != s.Player {
+				t.Errorf("teleported from %v safe %v, want from %v safe %v", e.From, e.Safe, s.Player, tt.safe)
+			}
+			if tt.safe && next.Phase != PhasePlaying {
+				t.Errorf("caught after a safe teleport")
+			}
+			if tt.safe && next.SafeTeleports != s.SafeTeleports-1 {
+				t.Errorf("%d safe teleports left, want %d", next.SafeTeleports, s.SafeTeleports-1)
+			}
+			if !tt.safe && next.Teleports != s.Teleports-1 {
+				t.Errorf("%d teleports left, want %d", next.Teleports, s.Teleports-1)
+			}
+		})
+	}
+}
+
+func TestFastDaleks(t *testing.T) {
+	tests := []struct {
+		name       string
+		board      string
+		wantDaleks []Position
+		wantScore  int
+	}{
+		{
+			name: "two steps",
+			board: `
+				..F..
+				.....
+				.....
+				.....
+				.....
+				..@..`,
+			wantDaleks: []Position{{X: 2, Y: 2}},
+		},
+		{
+			name: "crash on the first step",
+			board: `
+				..F..
+				..*..
+				.....
+				.....
+				+....
+				..@..`,
+			wantDaleks: []Position{{X: 1, Y: 5}},
+			wantScore:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseBoard(t, DefaultRules(), tt.board)
+			next, _ := Step(s, Action{Kind: ActionWait})
+			if got := dalekPositions(next); !slices.Equal(got, tt.wantDaleks) {
+				t.Errorf("daleks at %v, want %v", got, tt.wantDaleks)
+			}
+			if next.Score != tt.wantScore {
+				t.Errorf("score %d, want %d", next.Score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestArmour(t *testing.T) {
+	tests := []struct {
+		name        string
+		board       string
+		wantDaleks  []Dalek
+		wantScraps  []Position
+		wantScore   int
+		wantDamaged bool
+	}{
+		{
+			name: "bounces off scrap",
+			board: `
+				..A..
+				..*..
+				.....
+				+....
+				..@..`,
+			wantDaleks:  []Dalek{{Pos: Position{X: 2, Y: 0}, Kind: DalekArmoured}, {Pos: Position{X: 1, Y: 4}}},
+			wantScraps:  []Position{{X: 2, Y: 1}},
+			wantScore:   1,
+			wantDamaged: true,
+		},
+		{
+			name: "bounces off a crash",
+			board: `
+				.A.+.
+				.....
+				.....
+				.....
+				+.@..`,
+			wantDaleks:  []Dalek{{Pos: Position{X: 1, Y: 0}, Kind: DalekArmoured}, {Pos: Position{X: 1, Y: 4}}},
+			wantScraps:  []Position{{X: 2, Y: 1}},
+			wantScore:   1 + 2,
+			wantDamaged: true,
+		},
+		{
+			name: "damaged dalek is destroyed",
+			board: `
+				..a..
+				..*..
+				.....
+				+....
+				..@..`,
+			wantDaleks: []Dalek{{Pos: Position{X: 1, Y: 4}}},
+			wantScraps: []Position{{X: 2, Y: 1}},
+			wantScore:  2,
+		},
+		{
+			name: "standing its ground",
+			board: `
+				.....
+				.F...
+				.A...
+				.....
+				.....
+				.....
+				.@...`,
+			wantDaleks:  []Dalek{{Pos: Position{X: 1, Y: 3}, Kind: DalekArmoured}},
+			wantScore:   1 + 2,
+			wantDamaged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseBoard(t, DefaultRules(), tt.board)
+			next, events := Step(s, Action{Kind: ActionWait})
+			if !slices.Equal(next.Daleks, tt.wantDaleks) {
+				t.Errorf("daleks %v, want %v", next.Daleks, tt.wantDaleks)
+			}
+			if !slices.Equal(next.Scraps, tt.wantScraps) {
+				t.Errorf("scrap at %v, want %v", next.Scraps, tt.wantScraps)
+			}
+			if next.Score != tt.wantScore {
+				t.Errorf("score %d, want %d", next.Score, tt.wantScore)
+			}
+			if damaged := hasEvent(events, EventDalekDamaged); damaged != tt.wantDamaged {
+				t.Errorf("armour damaged %v, events %v", damaged, events)
+			}
+		})
+	}
+}
+
+func TestEmperor(t *testing.T) {
+	tests := []struct {
+		name        string
+		board       string
+		action      Action
+		wantHealth  int // Emperor health after the turn, 0 if destroyed
+		wantDaleks  int
+		wantScore   int
+		wantEvent   EventKind
+		wantSummons int
+	}{
+		{
+			name: "rests on odd turns",
+			board: `
+				Turns: 0
+				.......
+				.......
+				.......
+				...EE..
+				...EE..
+				.......
+				...@...`,
+			action:     Action{Kind: ActionWait},
+			wantHealth: 3,
+			wantDaleks: 1,
+			wantEvent:  EventDaleksMoved,
+		},
+		{
+			name: "hit by a dalek",
+			board: `
+				Turns: 0
+				.......
+				.......
+				...+...
+				...EE..
+				...EE..
+				.......
+				...@...`,
+			action:     Action{Kind: ActionWait},
+			wantHealth: 2,
+			wantDaleks: 1,
+			wantScore:  2,
+			wantEvent:  EventEmperorHit,
+		},
+		{
+			name: "destroyed",
+			board: `
+				Turns: 0  Emperor: 1
+				...+...
+				.......
+				...+...
+				...EE..
+				...EE..
+				.......
+				...@...`,
+			action:     Action{Kind: ActionWait},
+			wantDaleks: 1,
+			wantScore:  2 + 50,
+			wantEvent:  EventEmperorDestroyed,
+		},
+		{
+			name: "immune to the screwdriver",
+			board: `
+				Turns: 0
+				.......
+				.......
+				.......
+				...EE..
+				...EE..
+				....@..
+				+......`,
+			action:     Action{Kind: ActionScrewdriver},
+			wantHealth: 3,
+			wantDaleks: 2,
+			wantEvent:  EventScrewdriverFired,
+		},
+		{
+			name: "summons reinforcements",
+			board: `
+				Turns: 3
+				.........
+				.........
+				...EE....
+				...EE....
+				.........
+				.........
+				.........
+				.........
+				....@....`,
+			action:      Action{Kind: ActionWait},
+			wantHealth:  3,
+			wantDaleks:  3,
+			wantEvent:   EventEmperorSummoned,
+			wantSummons: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseBoard(t, DefaultRules(), tt.board)
+			next, events := Step(s, tt.action)
+			if events == nil {
+				t.Fatal("action was refused")
+			}
+			if next.Phase != PhasePlaying {
+				t.Fatalf("phase %s, want %s", next.Phase, PhasePlaying)
+			}
+
+			health := 0
+			for _, dalek := range next.Daleks {
+				if dalek.Kind == DalekEmperor {
+					health = dalek.Health
+				}
+			}
+			if health != tt.wantHealth {
+				t.Errorf("Emperor health %d, want %d", health, tt.wantHealth)
+			}
+			if len(next.Daleks) != tt.wantDaleks {
+				t.Errorf("%d daleks, want %d", len(next.Daleks), tt.wantDaleks)
+			}
+			if next.Score != tt.wantScore {
+				t.Errorf("score %d, want %d", next.Score, tt.wantScore)
+			}
+
+			e := findEvent(t, events, tt.wantEvent)
+			if tt.wantEvent == EventEmperorDestroyed {
+				for _, cell := range e.Targets {
+					if !next.HasScrap(cell) {
+						t.Errorf("no scrap left on the Emperor's cell %v", cell)
+					}
+				}
+			}
+			if tt.wantSummons > 0 {
+				if len(e.Targets) != tt.wantSummons {
+					t.Errorf("summoned %d daleks, want %d", len(e.Targets), tt.wantSummons)
+				}
+				for _, pos := range e.Targets {
+					if IsAdjacent(pos, next.Player) {
+						t.Errorf("dalek summoned next to the player at %v", pos)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEmperorNoScrapUnderCrash(t *testing.T) {
+	s := parseBoard(t, DefaultRules(), `
+		Turns: 0
+		.......
+		.......
+		...+...
+		...EE..
+		...EE..
+		.......
+		...@...`)
+
+	next, events := Step(s, Action{Kind: ActionWait})
+	if crash := findEvent(t, events, EventDaleksCollided); !crash.Held {
+		t.Errorf("crash into the Emperor was not held")
+	}
+	if len(next.Scraps) != 0 {
+		t.Errorf("scrap left under the Emperor at %v", next.Scraps)
+	}
+}
+
+func TestBossLevelFootprint(t *testing.T) {
+	// Every cell but the player's is taken by a dalek or scrap, with the
+	// Emperor filling four of them
+	rules := DefaultRules()
+	rules.Width, rules.Height = 10, 10
+	rules.MinSpawnDistance = 0
+	rules.BaseDaleks = 6
+	rules.DaleksPerLevel = 0
+	rules.BossEvery = 1
+	rules.ScrapHeaps = 100 - 1 - 6 - 3
+	if err := rules.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	s, _ := NewState(rules, 3)
+	cells := 1 + len(s.Scraps)
+	for _, dalek := range s.Daleks {
+		cells += len(dalek.Cells())
+	}
+	if cells != 100 {
+		t.Errorf("board has %d of 100 cells taken", cells)
+	}
+
+	rules.ScrapHeaps++
+	if err := rules.Validate(); err == nil {
+		t.Errorf("Validate allowed more scrap than fits around the Emperor")
+	}
+}
+
+func TestNewStateDeterministic(t *testing.T) {
+	for _, p := range Presets() {
+		t.Run(p.Name, func(t *testing.T) {
+			a, _ := NewStateAt(p.Rules, 1234, 5)
+			b, _ := NewStateAt(p.Rules, 1234, 5)
+			if !reflect.DeepEqual(a, b) {
+				t.Errorf("same seed gave different games:\n%+v\n%+v", a, b)
+			}
+			if err := a.Validate(); err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+
+			c, _ := NewStateAt(p.Rules, 1235, 5)
+			if a.Player == c.Player && slices.Equal(dalekPositions(a), dalekPositions(c)) {
+				t.Errorf("seeds 1234 and 1235 gave the same board")
+			}
+		})
+	}
+}
+
+func TestStepDoesNotChangeInput(t *testing.T) {
+	s, _ := NewState(DefaultRules(), 99)
+	before := s.Clone()
+	actions := []Action{{Kind: ActionWait}, {Kind: ActionTeleport}, {Kind: ActionSafeTeleport},
+		{Kind: ActionScrewdriver}, {Kind: ActionLastStand}, {Kind: ActionWaitUntilSafe}}
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			actions = append(actions, MoveAction(dx, dy))
+		}
+	}
+
+	for _, a := range actions {
+		Step(s, a)
+	}
+	if !reflect.DeepEqual(s, before) {
+		t.Errorf("Step changed the state it was given:\n%+v\n%+v", before, s)
+	}
+}
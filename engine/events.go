@@ -0,0 +1,99 @@
+package engine
+
+import "fmt"
+
+// EventKind identifies what happened during a Step
+type EventKind int
+
+const (
+	EventDaleksMoved EventKind = iota
+	EventDalekHitScrap
+	EventDaleksCollided
+	EventPlayerTeleported
+	EventScrewdriverFired
+	EventPlayerCaught
+	EventLevelCleared
+	EventLevelStarted
+	EventGameWon
+	EventLastStandStarted
+	EventLastStandEnded
+	EventWaitStarted
+	EventWaitEnded
+	EventOutOfTurns
+	EventDalekDamaged
+	EventEmperorHit
+	EventEmperorDestroyed
+	EventEmperorSummoned
+)
+
+var eventNames = map[EventKind]string{
+	EventDaleksMoved:      "DaleksMoved",
+	EventDalekHitScrap:    "DalekHitScrap",
+	EventDaleksCollided:   "DaleksCollided",
+	EventPlayerTeleported: "PlayerTeleported",
+	EventScrewdriverFired: "ScrewdriverFired",
+	EventPlayerCaught:     "PlayerCaught",
+	EventLevelCleared:     "LevelCleared",
+	EventLevelStarted:     "LevelStarted",
+	EventGameWon:          "GameWon",
+	EventLastStandStarted: "LastStandStarted",
+	EventLastStandEnded:   "LastStandEnded",
+	EventWaitStarted:      "WaitStarted",
+	EventWaitEnded:        "WaitEnded",
+	EventOutOfTurns:       "OutOfTurns",
+	EventDalekDamaged:     "DalekDamaged",
+	EventEmperorHit:       "EmperorHit",
+	EventEmperorDestroyed: "EmperorDestroyed",
+	EventEmperorSummoned:  "EmperorSummoned",
+}
+
+// String returns the event kind's name
+func (k EventKind) String() string {
+	if name, ok := eventNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// Move describes a single dalek moving from one cell to another
+type Move struct {
+	From, To Position
+	Kind     DalekKind
+	Health   int // Armour left after the move
+}
+
+// Event is emitted by Step so frontends can animate and play sounds
+// without knowing the rules
+type Event struct {
+	Kind    EventKind
+	Pos     Position   // Where the event happened
+	From    Position   // Teleport origin, or where a damaged dalek bounces back to
+	Safe    bool       // Teleport was a safe teleport
+	Held    bool       // An armoured dalek standing its ground or the Emperor took the crash, so no scrap was left
+	Moves   []Move     // Dalek moves, in dalek order; fast daleks take a second step in another event
+	Targets []Position // Daleks destroyed by the screwdriver or summoned by the Emperor, or the fallen Emperor's cells
+	Level   int        // Level cleared or started
+	Count   int        // Daleks destroyed by this event or during a wait, or turns taken on a cleared level
+	Points  int        // Score awarded by this event
+}
+
+// String describes the event for logs
+func (e Event) String() string {
+	s := fmt.Sprintf("%s at %d,%d", e.Kind, e.Pos.X, e.Pos.Y)
+	switch e.Kind {
+	case EventPlayerTeleported:
+		s += fmt.Sprintf(" from %d,%d safe=%v", e.From.X, e.From.Y, e.Safe)
+	case EventDaleksMoved:
+		s = fmt.Sprintf("%s (%d daleks)", e.Kind, len(e.Moves))
+	case EventScrewdriverFired:
+		s += fmt.Sprintf(" hit %d", len(e.Targets))
+	case EventEmperorSummoned:
+		s += fmt.Sprintf(" summoned %d", len(e.Targets))
+	case EventLevelCleared, EventLevelStarted, EventGameWon:
+		s += fmt.Sprintf(" level %d", e.Level)
+	}
+	if e.Points != 0 {
+		s += fmt.Sprintf(" %+d points", e.Points)
+	}
+	return s
+}
@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Pack is an ordered list of authored levels played as one game
+type Pack struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Rules       Rules       `json:"rules"` // Starting items, refills and scoring shared by every level
+	Levels      []PackLevel `json:"levels"`
+}
+
+// PackLevel describes one level of a pack. Settings left at zero keep the
+// pack's rules.
+type PackLevel struct {
+	Name  string `json:"name,omitempty"`
+	Intro string `json:"intro,omitempty"` // Shown when the level starts
+
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+
+	// Hand-placed board, or random placement of this many daleks and
+	// scrap heaps
+	Layout *Layout `json:"layout,omitempty"`
+	Daleks int     `json:"daleks,omitempty"`
+	Scraps int     `json:"scraps,omitempty"`
+
+	Brain string `json:"brain,omitempty"` // How the level's daleks move, instead of the pack's brain
+	Boss  bool   `json:"boss,omitempty"`  // The Emperor joins a randomly placed level
+
+	Grant Items `json:"grant"` // Items added when the level starts
+
+	Par      int `json:"par,omitempty"`      // Turns the author needed, shown to the player
+	MaxTurns int `json:"maxTurns,omitempty"` // Turns allowed before the game is lost, 0 for no limit
+}
+
+// Items is a bundle of player items
+type Items struct {
+	Teleports     int `json:"teleports,omitempty"`
+	SafeTeleports int `json:"safeTeleports,omitempty"`
+	Screwdrivers  int `json:"screwdrivers,omitempty"`
+	LastStands    int `json:"lastStands,omitempty"`
+}
+
+// Level returns the given 1-based level, clamped to the pack
+func (p Pack) Level(level int) PackLevel {
+	return p.Levels[min(max(level, 1), len(p.Levels))-1]
+}
+
+// LevelRules returns the rules a level of the pack is played with
+func (p Pack) LevelRules(level int) Rules {
+	l := p.Level(level)
+	r := p.Rules
+	r.Name = p.Name
+	r.MaxLevel = len(p.Levels)
+	r.MaxTurns = l.MaxTurns
+	r.Layout = l.Layout
+
+	if l.Width > 0 {
+		r.Width = l.Width
+	}
+	if l.Height > 0 {
+		r.Height = l.Height
+	}
+	if l.Daleks > 0 {
+		r.BaseDaleks = l.Daleks
+		r.DaleksPerLevel = 0
+		r.MaxDaleks = 0
+		r.MaxDensity = 0
+	}
+	if l.Scraps > 0 {
+		r.ScrapHeaps = l.Scraps
+	}
+	if l.Brain != "" {
+		r.Brain = l.Brain
+		r.LevelBrains = nil
+	}
+
+	// Pack levels only have the Emperor when they ask for it
+	r.BossEvery = 0
+	if l.Boss {
+		r.BossEvery = 1
+	}
+	return r
+}
+
+// Validate reports problems with the pack and each of its levels
+func (p Pack) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(p.Name != "", "name is missing")
+	check(len(p.Name) <= maxRulesName, "name must be at most %d characters", maxRulesName)
+	check(len(p.Levels) > 0, "needs at least one level")
+	check(p.Rules.Layout == nil, "rules: layouts belong on a level")
+
+	for i, l := range p.Levels {
+		var levelErrs []error
+		add := func(ok bool, format string, args ...any) {
+			if !ok {
+				levelErrs = append(levelErrs, fmt.Errorf(format, args...))
+			}
+		}
+
+		add(l.Layout == nil || (l.Daleks == 0 && l.Scraps == 0), "daleks and scraps cannot be combined with a layout")
+		add(l.Layout == nil || !l.Boss, "boss cannot be combined with a layout")
+		add(l.Width >= 0 && l.Height >= 0, "width and height must not be negative")
+		add(l.Daleks >= 0 && l.Scraps >= 0, "daleks and scraps must not be negative")
+		add(l.Grant.Teleports >= 0 && l.Grant.SafeTeleports >= 0 && l.Grant.Screwdrivers >= 0 && l.Grant.LastStands >= 0,
+			"grant must not be negative")
+		add(l.Par >= 0, "par must not be negative (got %d)", l.Par)
+		add(l.MaxTurns == 0 || l.Par <= l.MaxTurns, "par must be no more than maxTurns (got par %d, maxTurns %d)", l.Par, l.MaxTurns)
+		if len(levelErrs) == 0 {
+			if err := p.LevelRules(i + 1).Validate(); err != nil {
+				levelErrs = append(levelErrs, err)
+			}
+		}
+
+		if len(levelErrs) > 0 {
+			label := fmt.Sprintf("level %d", i+1)
+			if l.Name != "" {
+				label += fmt.Sprintf(" (%s)", l.Name)
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", label, errors.Join(levelErrs...)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ParsePack decodes and validates a JSON level pack. Rules missing from the
+// data keep their default values; unknown settings are an error.
+func ParsePack(data []byte) (*Pack, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("pack file is empty")
+	}
+
+	p := &Pack{Rules: DefaultRules()}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(p); err != nil {
+		return nil, describeJSONError(data, dec.InputOffset(), err)
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after the pack object")
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pack %q:\n%w", p.Name, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preset is a named set of rules players can pick from
+type Preset struct {
+	Name        string
+	Description string
+	Rules       Rules
+}
+
+// Presets returns the built-in presets: the difficulty levels, easiest
+// first, followed by the compatibility modes
+func Presets() []Preset {
+	easy := DefaultRules()
+	easy.Name = "Easy"
+	easy.StartTeleports = 12
+	easy.StartSafeTeleports = 5
+	easy.StartScrewdrivers = 3
+	easy.StartLastStands = 2
+	easy.BaseDaleks = 2
+	easy.DaleksPerLevel = 1
+	easy.MinSpawnDistance = 25 // Nothing within five cells
+	easy.SafeTeleportDistance = 8
+	easy.TeleportRefill = 3
+	easy.ScrewdriverRefill = 2
+	easy.LastStandBonusEvery = 3
+
+	hard := DefaultRules()
+	hard.Name = "Hard"
+	hard.StartTeleports = 8
+	hard.StartSafeTeleports = 2
+	hard.StartScrewdrivers = 1
+	hard.BaseDaleks = 8
+	hard.DaleksPerLevel = 2
+	hard.MinSpawnDistance = 2
+	hard.TeleportRefill = 1
+	hard.ScrewdriverRefill = 1
+	hard.Spawns = []Spawn{
+		{Kind: DalekFast, From: 5, Weight: 10, PerLevel: 5, Max: 40},
+		{Kind: DalekArmoured, From: 8, Weight: 5, PerLevel: 5, Max: 30},
+	}
+
+	nightmare := DefaultRules()
+	nightmare.Name = "Nightmare"
+	nightmare.StartTeleports = 5
+	nightmare.StartSafeTeleports = 1
+	nightmare.StartScrewdrivers = 1
+	nightmare.StartLastStands = 0
+	nightmare.BaseDaleks = 12
+	nightmare.DaleksPerLevel = 3
+	nightmare.MinSpawnDistance = 2
+	nightmare.SafeTeleportDistance = 1 // Diagonal neighbours count as safe
+	nightmare.TeleportRefill = 1
+	nightmare.ScrewdriverRefill = 0
+	nightmare.LastStandsPerLevel = 0
+	nightmare.Spawns = []Spawn{
+		{Kind: DalekFast, From: 3, Weight: 10, PerLevel: 5, Max: 45},
+		{Kind: DalekArmoured, From: 6, Weight: 10, PerLevel: 5, Max: 40},
+	}
+
+	// BSD robots(6): unlimited but unsafe teleports, ten more robots every
+	// level up to forty, and no final level. The board is narrower than the
+	// 60-column original so it fits the window.
+	robots := Rules{
+		Name:               "Robots",
+		Width:              50,
+		Height:             22,
+		DaleksPerLevel:     10,
+		MaxDaleks:          40,
+		UnlimitedTeleports: true,
+		CrashPoints:        10,
+		WaitBonus:          10,
+	}
+
+	// Modelled on the 1984 Macintosh Daleks: unlimited random teleports, a
+	// safe teleport earned for every level cleared, one screwdriver charge
+	// per level and a Last Stand that can always be called
+	classic := Rules{
+		Name:                 "Classic",
+		Width:                50,
+		Height:               35,
+		DaleksPerLevel:       5,
+		MinSpawnDistance:     2,
+		MaxDaleks:            100,
+		SafeTeleportDistance: 2,
+		UnlimitedTeleports:   true,
+		UnlimitedLastStands:  true,
+		SafeTeleportRefill:   1,
+		StartScrewdrivers:    1,
+		ScrewdriverRecharge:  1,
+		CrashPoints:          10,
+		ScrewdriverPoints:    10,
+		Monochrome:           true,
+	}
+
+	// Normal rules without a final level. Dalek numbers grow faster, stop at
+	// 4% of the board and then close in around the player, while refills
+	// shrink every ten levels. Fast daleks join from level 6.
+	endless := DefaultRules()
+	endless.Name = "Endless"
+	endless.DaleksPerLevel = 2
+	endless.MaxDensity = 4
+	endless.RefillTaperEvery = 10
+	endless.MaxLevel = 0
+	endless.Spawns = []Spawn{
+		{Kind: DalekFast, From: 6, Weight: 5, PerLevel: 1, Max: 40},
+		{Kind: DalekArmoured, From: 10, Weight: 5, PerLevel: 1, Max: 30},
+	}
+
+	return []Preset{
+		{Name: "Easy", Description: "fewer daleks, more items, roomier teleports", Rules: easy},
+		{Name: "Normal", Description: "the standard game", Rules: DefaultRules()},
+		{Name: "Hard", Description: "more daleks, fewer refills", Rules: hard},
+		{Name: "Nightmare", Description: "crowded boards, almost no help", Rules: nightmare},
+		{Name: "Endless", Description: "no final level; how deep can you go?", Rules: endless},
+		{Name: "Robots", Description: "BSD robots: unlimited teleports, W waits", Rules: robots},
+		{Name: "Classic", Description: "1984 Mac Daleks in black and white", Rules: classic},
+	}
+}
+
+// PresetRules returns the rules of the preset with the given name, ignoring case
+func PresetRules(name string) (Rules, error) {
+	presets := Presets()
+	names := make([]string, 0, len(presets))
+	for _, p := range presets {
+		if strings.EqualFold(p.Name, name) {
+			return p.Rules, nil
+		}
+		names = append(names, strings.ToLower(p.Name))
+	}
+	return DefaultRules(), fmt.Errorf("unknown preset %q (choose %s)", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,83 @@
+package engine
+
+// Preview shows where the daleks would go if the player ended the turn on a
+// cell, and which of them would crash
+type Preview struct {
+	Daleks  []Position // Every cell a dalek would stop on, a fast dalek's midpoint and each of the Emperor's cells included
+	Crashes []Position // Cells where daleks would crash into each other or into scrap
+	Caught  bool       // A dalek would land on the player
+}
+
+// PreviewMove works out the daleks' next move with the player on cell,
+// using the same rule as a real turn
+func (s State) PreviewMove(cell Position) Preview {
+	next := s.Clone()
+	next.Player = cell
+	next.Turns++ // The Emperor rests on the same turns as in play
+	var events []Event
+
+	var p Preview
+	for step := 0; step == 0 || next.stepsLeft(step); step++ {
+		moves := next.moveDaleks(step, &events)
+
+		landed := make(map[Position]int, len(next.Daleks))
+		for _, dalek := range next.Daleks {
+			for _, pos := range dalek.Cells() {
+				landed[pos]++
+			}
+		}
+
+		// Report each crash site once, in dalek order. Daleks that sit out
+		// the turn still stop where they are.
+		for _, dalek := range next.Daleks {
+			moved := step == 0 || next.moves(dalek, step)
+			for _, pos := range dalek.Cells() {
+				if moved {
+					p.Daleks = append(p.Daleks, pos)
+				}
+				p.Caught = p.Caught || pos == cell
+				if landed[pos] == 0 || (landed[pos] < 2 && !next.HasScrap(pos)) {
+					continue
+				}
+				p.Crashes = append(p.Crashes, pos)
+				landed[pos] = 0
+			}
+		}
+
+		if p.Caught {
+			break
+		}
+		next.resolveCollisions(moves, &events)
+	}
+	return p
+}
+
+// CanMoveTo reports whether the player could end the turn on cell by
+// moving or waiting
+func (s State) CanMoveTo(cell Position) bool {
+	near := cell == s.Player || IsAdjacent(cell, s.Player)
+	return near && s.InBounds(cell) && !s.HasScrap(cell)
+}
+
+// Danger returns every cell a dalek could land on next turn, whichever move
+// the player makes
+func (s State) Danger() []Position {
+	var cells []Position
+	seen := make(map[Position]bool)
+
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			cell := Position{X: s.Player.X + dx, Y: s.Player.Y + dy}
+			if !s.CanMoveTo(cell) {
+				continue
+			}
+			for _, pos := range s.PreviewMove(cell).Daleks {
+				if !seen[pos] {
+					seen[pos] = true
+					cells = append(cells, pos)
+				}
+			}
+		}
+	}
+	return cells
+}
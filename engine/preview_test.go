@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPreviewMove(t *testing.T) {
+	s := parseBoard(t, DefaultRules(), `
+		.+.+.
+		.....
+		..@..
+		.....
+		.....`)
+
+	tests := []struct {
+		name        string
+		cell        Position
+		wantCrashes []Position
+		wantCaught  bool
+	}{
+		{"stay", Position{X: 2, Y: 2}, []Position{{X: 2, Y: 1}}, false},
+		{"step back", Position{X: 2, Y: 3}, []Position{{X: 2, Y: 1}}, false},
+		{"step left", Position{X: 1, Y: 2}, nil, false},
+		{"step into the crash", Position{X: 2, Y: 1}, []Position{{X: 2, Y: 1}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := s.PreviewMove(tt.cell)
+			if !slices.Equal(p.Crashes, tt.wantCrashes) {
+				t.Errorf("crashes at %v, want %v", p.Crashes, tt.wantCrashes)
+			}
+			if p.Caught != tt.wantCaught {
+				t.Errorf("caught %v, want %v", p.Caught, tt.wantCaught)
+			}
+		})
+	}
+}
+
+func TestPreviewMatchesStep(t *testing.T) {
+	for _, p := range Presets() {
+		t.Run(p.Name, func(t *testing.T) {
+			s, _ := NewStateAt(p.Rules, 8, 5)
+			for dx := -1; dx <= 1; dx++ {
+				for dy := -1; dy <= 1; dy++ {
+					cell := Position{X: s.Player.X + dx, Y: s.Player.Y + dy}
+					if !s.CanMoveTo(cell) {
+						continue
+					}
+					next, _ := Step(s, MoveAction(dx, dy))
+					caught := next.Phase == PhaseGameOver
+					if preview := s.PreviewMove(cell); preview.Caught != caught {
+						t.Errorf("move %d,%d: preview caught %v, step caught %v", dx, dy, preview.Caught, caught)
+					}
+				}
+			}
+		})
+	}
+}
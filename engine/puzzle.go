@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Puzzle is a hand-made board to be cleared within a number of turns
+type Puzzle struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Layout
+
+	// Item allowance
+	Teleports     int `json:"teleports"`
+	SafeTeleports int `json:"safeTeleports"`
+	Screwdrivers  int `json:"screwdrivers"`
+	LastStands    int `json:"lastStands"`
+
+	Par      int `json:"par"`      // Turns needed for three stars
+	MaxTurns int `json:"maxTurns"` // Turns allowed before the puzzle is failed
+}
+
+// Rules returns the rules the puzzle is played with: the standard scoring
+// on its own board, with no refills and a single level
+func (p Puzzle) Rules() Rules {
+	rules := DefaultRules()
+	rules.Name = "Puzzle"
+	rules.Width = p.Width
+	rules.Height = p.Height
+
+	rules.StartTeleports = p.Teleports
+	rules.StartSafeTeleports = p.SafeTeleports
+	rules.StartScrewdrivers = p.Screwdrivers
+	rules.StartLastStands = p.LastStands
+	rules.TeleportRefill = 0
+	rules.ScrewdriverRefill = 0
+	rules.LastStandsPerLevel = 0
+	rules.LastStandBonusEvery = 0
+
+	rules.MaxLevel = 1
+	rules.MaxTurns = p.MaxTurns
+
+	layout := p.Layout
+	rules.Layout = &layout
+	return rules
+}
+
+// Start returns the puzzle's starting state
+func (p Puzzle) Start() (State, []Event) {
+	return NewState(p.Rules(), 0)
+}
+
+// Stars rates a solution: three at or under par, two within halfway from
+// par to the turn limit, and one for any other solution
+func (p Puzzle) Stars(turns int) int {
+	switch {
+	case turns <= p.Par:
+		return 3
+	case turns <= p.Par+(p.MaxTurns-p.Par)/2:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Validate reports problems with the puzzle's board or turn counts
+func (p Puzzle) Validate() error {
+	var errs []error
+	if p.Name == "" {
+		errs = append(errs, errors.New("name is missing"))
+	}
+	if p.Par < 1 || p.MaxTurns < p.Par {
+		errs = append(errs, fmt.Errorf("par must be at least 1 and no more than maxTurns (got par %d, maxTurns %d)", p.Par, p.MaxTurns))
+	}
+	if err := p.Rules().Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// ParsePuzzles decodes and validates a JSON list of puzzles
+func ParsePuzzles(data []byte) ([]Puzzle, error) {
+	var puzzles []Puzzle
+	if err := json.Unmarshal(data, &puzzles); err != nil {
+		return nil, err
+	}
+
+	for i, p := range puzzles {
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("puzzle %d (%s): %w", i+1, p.Name, err)
+		}
+	}
+	return puzzles, nil
+}
@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	replayMagic   = "GDRP"
+	replayVersion = 1
+
+	maxReplayActions = 1 << 24
+)
+
+// Replay is a complete game: the seed and rules it started from, every
+// action the player took, and the outcome the recorder claims
+type Replay struct {
+	Seed       uint64
+	StartLevel int
+	Rules      Rules
+	Pack       *Pack // Level pack the game was played through, if any
+	Actions    []Action
+
+	// Claimed outcome
+	Phase Phase
+	Level int
+	Score int
+}
+
+// replayHeader is the JSON block at the start of a replay file
+type replayHeader struct {
+	Seed       uint64 `json:"seed"`
+	StartLevel int    `json:"startLevel,omitempty"`
+	Rules      Rules  `json:"rules"`
+	Pack       *Pack  `json:"pack,omitempty"`
+	Actions    int    `json:"actions"`
+	Phase      Phase  `json:"phase"`
+	Level      int    `json:"level"`
+	Score      int    `json:"score"`
+}
+
+// NewReplay records the outcome of a game played from seed with actions
+func NewReplay(final State, actions []Action) Replay {
+	return Replay{
+		Seed:       final.Seed,
+		StartLevel: final.StartLevel,
+		Rules:      final.Rules,
+		Pack:       final.Pack,
+		Actions:    append([]Action(nil), actions...),
+		Phase:      final.Phase,
+		Level:      final.Level,
+		Score:      final.Score,
+	}
+}
+
+// encodeAction packs an action into a single byte: the kind in the high
+// nibble and the move direction in the low nibble
+func encodeAction(a Action) byte {
+	dir := byte((a.DX+1)*3 + (a.DY + 1))
+	return byte(a.Kind)<<4 | dir
+}
+
+// decodeAction unpacks a byte written by encodeAction
+func decodeAction(b byte) (Action, error) {
+	kind := ActionKind(b >> 4)
+	dir := int(b & 0x0f)
+	if kind > ActionWaitUntilSafe || dir > 8 {
+		return Action{}, fmt.Errorf("invalid action byte 0x%02x", b)
+	}
+
+	a := Action{Kind: kind}
+	if kind == ActionMove {
+		a.DX = dir/3 - 1
+		a.DY = dir%3 - 1
+	}
+	return a, nil
+}
+
+// WriteReplay writes a replay in the compact binary replay format: a magic
+// string and version, a length-prefixed JSON header, then one byte per action
+func WriteReplay(w io.Writer, r Replay) error {
+	header, err := json.Marshal(replayHeader{
+		Seed:       r.Seed,
+		StartLevel: r.StartLevel,
+		Rules:      r.Rules,
+		Pack:       r.Pack,
+		Actions:    len(r.Actions),
+		Phase:      r.Phase,
+		Level:      r.Level,
+		Score:      r.Score,
+	})
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(replayMagic)
+	bw.WriteByte(replayVersion)
+
+	var size [binary.MaxVarintLen64]byte
+	bw.Write(size[:binary.PutUvarint(size[:], uint64(len(header)))])
+	bw.Write(header)
+
+	for _, a := range r.Actions {
+		bw.WriteByte(encodeAction(a))
+	}
+	return bw.Flush()
+}
+
+// ReadReplay reads a replay written by WriteReplay
+func ReadReplay(r io.Reader) (Replay, error) {
+	var replay Replay
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(replayMagic)+1)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return replay, errors.New("not a GoDaleks replay file")
+	}
+	if string(magic[:len(replayMagic)]) != replayMagic {
+		return replay, errors.New("not a GoDaleks replay file")
+	}
+	if version := magic[len(replayMagic)]; version != replayVersion {
+		return replay, fmt.Errorf("replay version %d is not supported (expected %d)", version, replayVersion)
+	}
+
+	size, err := binary.ReadUvarint(br)
+	if err != nil || size > 1<<20 {
+		return replay, errors.New("replay header is corrupted")
+	}
+	headerData := make([]byte, size)
+	if _, err := io.ReadFull(br, headerData); err != nil {
+		return replay, errors.New("replay header is truncated")
+	}
+
+	var header replayHeader
+	if err := json.Unmarshal(headerData, &header); err != nil {
+		return replay, fmt.Errorf("replay header is corrupted: %w", err)
+	}
+
+	if err := header.Rules.Validate(); err != nil {
+		return replay, fmt.Errorf("replay rules are invalid: %w", err)
+	}
+	if header.Pack != nil {
+		if err := header.Pack.Validate(); err != nil {
+			return replay, fmt.Errorf("replay pack is invalid: %w", err)
+		}
+	}
+	if header.Actions < 0 || header.Actions > maxReplayActions {
+		return replay, fmt.Errorf("replay has an invalid action count %d", header.Actions)
+	}
+	actions := make([]byte, header.Actions)
+	if _, err := io.ReadFull(br, actions); err != nil {
+		return replay, errors.New("replay actions are truncated")
+	}
+
+	replay = Replay{
+		Seed:       header.Seed,
+		StartLevel: header.StartLevel,
+		Rules:      header.Rules,
+		Pack:       header.Pack,
+		Actions:    make([]Action, 0, len(actions)),
+		Phase:      header.Phase,
+		Level:      header.Level,
+		Score:      header.Score,
+	}
+	for i, b := range actions {
+		a, err := decodeAction(b)
+		if err != nil {
+			return replay, fmt.Errorf("action %d: %w", i+1, err)
+		}
+		replay.Actions = append(replay.Actions, a)
+	}
+	return replay, nil
+}
+
+// Start returns the state the replay begins from
+func (r Replay) Start() (State, []Event) {
+	if r.Pack != nil {
+		return NewPackState(r.Pack, r.Seed, r.StartLevel)
+	}
+	return NewStateAt(r.Rules, r.Seed, r.StartLevel)
+}
+
+// Play re-simulates the replay from its seed and returns the final state.
+// It fails if any recorded action is not legal at the point it was played.
+func (r Replay) Play() (State, error) {
+	state, _ := r.Start()
+
+	for i, action := range r.Actions {
+		if state.Phase != PhasePlaying {
+			return state, fmt.Errorf("action %d was played after the game ended", i+1)
+		}
+
+		next, events := Step(state, action)
+		if events == nil {
+			return state, fmt.Errorf("action %d is not legal on level %d", i+1, state.Level)
+		}
+		state = next
+	}
+	return state, nil
+}
+
+// Verify re-simulates the replay and checks that the claimed outcome matches
+func (r Replay) Verify() (State, error) {
+	final, err := r.Play()
+	if err != nil {
+		return final, err
+	}
+
+	if final.Score != r.Score {
+		return final, fmt.Errorf("claimed score %d does not match simulated score %d", r.Score, final.Score)
+	}
+	if final.Level != r.Level {
+		return final, fmt.Errorf("claimed level %d does not match simulated level %d", r.Level, final.Level)
+	}
+	if final.Phase != r.Phase {
+		return final, fmt.Errorf("claimed outcome %q does not match simulated outcome %q", r.Phase, final.Phase)
+	}
+	return final, nil
+}
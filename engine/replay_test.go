@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// playActions are tried in order by playGame
+var playActions = []Action{
+	{Kind: ActionWait},
+	MoveAction(0, -1), MoveAction(0, 1), MoveAction(-1, 0), MoveAction(1, 0),
+	MoveAction(-1, -1), MoveAction(1, -1), MoveAction(-1, 1), MoveAction(1, 1),
+}
+
+// playGame plays up to turns actions and returns the final state and the
+// actions taken. Each turn it takes the first move that survives, and
+// teleports when none does.
+func playGame(rules Rules, seed uint64, turns int) (State, []Action) {
+	s, _ := NewState(rules, seed)
+	var actions []Action
+	for len(actions) < turns && s.Phase == PhasePlaying {
+		a := Action{Kind: ActionTeleport}
+		for _, try := range playActions {
+			if next, events := Step(s, try); events != nil && next.Phase != PhaseGameOver {
+				a = try
+				break
+			}
+		}
+		next, events := Step(s, a)
+		if events == nil {
+			break
+		}
+		s = next
+		actions = append(actions, a)
+	}
+	return s, actions
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	for _, p := range Presets() {
+		t.Run(p.Name, func(t *testing.T) {
+			final, actions := playGame(p.Rules, 77, 200)
+			replay := NewReplay(final, actions)
+
+			var buf bytes.Buffer
+			if err := WriteReplay(&buf, replay); err != nil {
+				t.Fatalf("WriteReplay: %v", err)
+			}
+			got, err := ReadReplay(&buf)
+			if err != nil {
+				t.Fatalf("ReadReplay: %v", err)
+			}
+
+			if !slices.Equal(got.Actions, actions) {
+				t.Errorf("actions %v, want %v", got.Actions, actions)
+			}
+			if got.Seed != replay.Seed || got.Score != replay.Score || got.Level != replay.Level || got.Phase != replay.Phase {
+				t.Errorf("header %+v, want %+v", got, replay)
+			}
+
+			played, err := got.Verify()
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if !reflect.DeepEqual(played, final) {
+				t.Errorf("replay ended on\n%+v\nwant\n%+v", played, final)
+			}
+		})
+	}
+}
+
+func TestReplayVerifyRejects(t *testing.T) {
+	final, actions := playGame(DefaultRules(), 5, 30)
+	if len(actions) < 2 {
+		t.Fatalf("game only lasted %d actions", len(actions))
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(r *Replay)
+		want   string
+	}{
+		{"score", func(r *Replay) { r.Score += 10 }, "claimed score"},
+		{"level", func(r *Replay) { r.Level++ }, "claimed level"},
+		{"outcome", func(r *Replay) { r.Phase = PhaseWin }, "claimed outcome"},
+		{"seed", func(r *Replay) { r.Seed++ }, ""},
+		{"extra action after the end", func(r *Replay) {
+			r.Actions = append(r.Actions, Action{Kind: ActionWait})
+			r.Phase = PhaseGameOver
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replay := NewReplay(final, actions)
+			tt.tamper(&replay)
+			if _, err := replay.Verify(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Verify error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadReplayErrors(t *testing.T) {
+	valid := func(edit func(h map[string]any)) []byte {
+		header := map[string]any{"seed": 1, "rules": DefaultRules(), "actions": 1, "phase": 0, "level": 1, "score": 0}
+		edit(header)
+		data, _ := json.Marshal(header)
+
+		var buf bytes.Buffer
+		buf.WriteString(replayMagic)
+		buf.WriteByte(replayVersion)
+		buf.Write(binary.AppendUvarint(nil, uint64(len(data))))
+		buf.Write(data)
+		buf.WriteByte(encodeAction(Action{Kind: ActionWait}))
+		return buf.Bytes()
+	}
+	withRules := func(edit func(r *Rules)) func(h map[string]any) {
+		return func(h map[string]any) {
+			rules := DefaultRules()
+			edit(&rules)
+			h["rules"] = rules
+		}
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "not a GoDaleks replay"},
+		{"wrong magic", []byte("NOPE\x01"), "not a GoDaleks replay"},
+		{"wrong version", []byte(replayMagic + "\x09"), "version 9"},
+		{"zero width", valid(withRules(func(r *Rules) { r.Width = 0 })), "rules are invalid"},
+		{"negative points", valid(withRules(func(r *Rules) { r.CrashPoints = -1 })), "rules are invalid"},
+		{"too many actions", valid(func(h map[string]any) { h["actions"] = 5 }), "truncated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadReplay(bytes.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestActionEncoding(t *testing.T) {
+	actions := []Action{{Kind: ActionWait}, {Kind: ActionTeleport}, {Kind: ActionSafeTeleport},
+		{Kind: ActionScrewdriver}, {Kind: ActionLastStand}, {Kind: ActionWaitUntilSafe}}
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			actions = append(actions, MoveAction(dx, dy))
+		}
+	}
+
+	for _, a := range actions {
+		got, err := decodeAction(encodeAction(a))
+		if err != nil || got != a {
+			t.Errorf("action %+v decoded as %+v, %v", a, got, err)
+		}
+	}
+	if _, err := decodeAction(0xff); err == nil {
+		t.Errorf("decodeAction(0xff) did not fail")
+	}
+}
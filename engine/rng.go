@@ -0,0 +1,39 @@
+package engine
+
+// RNG is a small SplitMix64 random number generator. Its whole state is a
+// single exported value, so it is copied, saved and restored along with the
+// State that owns it.
+type RNG struct {
+	State uint64 `json:"state"`
+}
+
+// NewRNG creates a generator from a seed
+func NewRNG(seed uint64) RNG {
+	return RNG{State: seed}
+}
+
+// Uint64 returns the next pseudo-random value
+func (r *RNG) Uint64() uint64 {
+	r.State += 0x9e3779b97f4a7c15
+	z := r.State
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Intn returns a pseudo-random number in [0, n)
+func (r *RNG) Intn(n int) int {
+	if n <= 0 {
+		panic("engine: invalid argument to Intn")
+	}
+	return int(r.Uint64() % uint64(n))
+}
+
+// levelRNG returns the generator used to lay out a level. It only depends on
+// the game seed and the level number, so the same seed always produces the
+// same boards no matter how many teleports were used on earlier levels.
+func levelRNG(seed uint64, level int) RNG {
+	rng := NewRNG(seed ^ uint64(level)*0xd1b54a32d192ed03)
+	rng.Uint64()
+	return rng
+}
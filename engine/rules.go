@@ -0,0 +1,545 @@
+package engine
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	minBoardSize = 5
+	maxBoardSize = 200
+	maxRulesName = 24
+
+	// Most daleks baseDaleks or daleksPerLevel may add, more than any board
+	// holds. It keeps dalek counts within 32 bits at any level.
+	maxRuleDaleks = maxBoardSize * maxBoardSize
+
+	// Ring spawns used once MaxDensity is reached
+	ringStartRadius = 8 // Cells between the player and the first ring
+	ringMinRadius   = 2 // Closest the ring ever starts
+	ringCloseEvery  = 3 // Levels per cell the ring closes in
+
+	rulesSchemaID = "https://github.com/AaronSaikovski/godaleks/rules.schema.json"
+)
+
+// RulesSchema is the JSON Schema describing rules files
+//
+//go:embed rules.schema.json
+var RulesSchema []byte
+
+// Rules holds every tunable number used by the rules engine
+type Rules struct {
+	Name string `json:"name,omitempty"` // Preset or rules file name shown to the player
+
+	Width  int `json:"width"`  // Board width in cells
+	Height int `json:"height"` // Board height in cells
+
+	// Starting inventory
+	StartTeleports     int `json:"startTeleports"`
+	StartSafeTeleports int `json:"startSafeTeleports"`
+	StartScrewdrivers  int `json:"startScrewdrivers"`
+	StartLastStands    int `json:"startLastStands"`
+
+	// Dalek placement
+	BaseDaleks       int `json:"baseDaleks"`       // Daleks on every level
+	DaleksPerLevel   int `json:"daleksPerLevel"`   // Extra daleks added per level number
+	MinSpawnDistance int `json:"minSpawnDistance"` // Minimum squared distance between a new dalek and the player
+	MaxDaleks        int `json:"maxDaleks"`        // Most daleks on a level, 0 for no limit
+	MaxDensity       int `json:"maxDensity"`       // Most daleks as a percentage of the board; deeper levels ring the player instead, 0 for no limit
+	ScrapHeaps       int `json:"scrapHeaps"`       // Scrap heaps scattered over every level
+
+	// Dalek kinds other than normal, and the chance of each being placed
+	Spawns []Spawn `json:"spawns,omitempty"`
+
+	// Dalek AI: the brain daleks use, levels from which they switch to
+	// another one, and brains for particular kinds of dalek on every level
+	Brain       string               `json:"brain,omitempty"`
+	LevelBrains []LevelBrain         `json:"levelBrains,omitempty"`
+	KindBrains  map[DalekKind]string `json:"kindBrains,omitempty"`
+
+	// Boss levels: the Emperor, a 2x2 dalek that moves every other turn,
+	// shrugs off the screwdriver and summons reinforcements until enough
+	// daleks crash into it
+	BossEvery     int `json:"bossEvery"`     // Every Nth level is a boss level, 0 for none
+	EmperorHealth int `json:"emperorHealth"` // Crashes it takes to destroy the Emperor
+	SummonEvery   int `json:"summonEvery"`   // Turns between reinforcements, 0 for none
+	Summons       int `json:"summons"`       // Daleks summoned each time
+
+	// Safe teleport never lands within this squared distance of a dalek
+	SafeTeleportDistance int `json:"safeTeleportDistance"`
+
+	// Items that are never used up
+	UnlimitedTeleports  bool `json:"unlimitedTeleports"`
+	UnlimitedLastStands bool `json:"unlimitedLastStands"`
+
+	// Per-level refills
+	TeleportRefill      int `json:"teleportRefill"`      // Teleports added when a level is cleared
+	SafeTeleportRefill  int `json:"safeTeleportRefill"`  // Safe teleports added when a level is cleared
+	ScrewdriverRefill   int `json:"screwdriverRefill"`   // Screwdrivers added when a level is cleared
+	ScrewdriverRecharge int `json:"screwdriverRecharge"` // Screwdrivers topped up to at least this many every level
+	LastStandsPerLevel  int `json:"lastStandsPerLevel"`  // Last Stands available at the start of every level
+	LastStandBonusEvery int `json:"lastStandBonusEvery"` // Extra Last Stand every N levels
+	RefillTaperEvery    int `json:"refillTaperEvery"`    // Refills shrink by one every N levels, never below one; 0 to keep them
+
+	// Progression
+	MaxLevel int `json:"maxLevel"` // Clearing this level wins the game, 0 to play on forever
+	MaxTurns int `json:"maxTurns"` // Turns allowed per level, 0 for no limit
+
+	// Scoring
+	CrashPoints       int `json:"crashPoints"`       // Per dalek destroyed by a crash
+	ScrewdriverPoints int `json:"screwdriverPoints"` // Per dalek destroyed by the screwdriver
+	ArmourPoints      int `json:"armourPoints"`      // Per crash an armoured dalek survives
+	EmperorPoints     int `json:"emperorPoints"`     // For destroying the Emperor
+	LevelBonus        int `json:"levelBonus"`        // Multiplied by the level number when it is cleared
+	LastStandBonus    int `json:"lastStandBonus"`    // Surviving a Last Stand with every dalek destroyed
+	WaitBonus         int `json:"waitBonus"`         // Extra points per dalek destroyed while waiting until safe
+
+	// Presentation hint: draw the board in 1-bit black and white
+	Monochrome bool `json:"monochrome"`
+
+	// Hand-placed board used instead of random placement, if any
+	Layout *Layout `json:"layout,omitempty"`
+}
+
+// Layout is a hand-placed starting board
+type Layout struct {
+	Player         Position   `json:"player"`
+	Daleks         []Position `json:"daleks"`
+	FastDaleks     []Position `json:"fastDaleks,omitempty"`
+	ArmouredDaleks []Position `json:"armouredDaleks,omitempty"`
+	Scraps         []Position `json:"scraps,omitempty"`
+}
+
+// Spawn is the chance, in percent, that a dalek placed on a level is of a
+// special kind. It starts at Weight on level From and grows by PerLevel
+// every level after, up to Max. Daleks that are not given a special kind
+// are normal, so the chances of every spawn can add up to at most 100.
+type Spawn struct {
+	Kind     DalekKind `json:"kind"`
+	From     int       `json:"from"`
+	Weight   int       `json:"weight"`
+	PerLevel int       `json:"perLevel,omitempty"`
+	Max      int       `json:"max,omitempty"` // Highest chance it grows to, 0 for 100
+}
+
+// WeightAt returns the spawn's chance on the given level
+func (sp Spawn) WeightAt(level int) int {
+	if level < sp.From {
+		return 0
+	}
+	return min(sp.Weight+sp.PerLevel*(level-sp.From), sp.peak())
+}
+
+// peak returns the highest chance the spawn reaches on any level
+func (sp Spawn) peak() int {
+	switch {
+	case sp.PerLevel == 0:
+		return sp.Weight
+	case sp.Max > 0:
+		return sp.Max
+	}
+	return 100
+}
+
+// DefaultRules returns the standard GoDaleks rules
+func DefaultRules() Rules {
+	return Rules{
+		Name: "Normal",
+
+		Width:  50,
+		Height: 35,
+
+		StartTeleports:     10,
+		StartSafeTeleports: 3,
+		StartScrewdrivers:  2,
+		StartLastStands:    1,
+
+		BaseDaleks:       5,
+		DaleksPerLevel:   1,
+		MinSpawnDistance: 3,
+
+		BossEvery:     5,
+		EmperorHealth: 3,
+		SummonEvery:   4,
+		Summons:       2,
+
+		SafeTeleportDistance: 2,
+
+		TeleportRefill:      2,
+		ScrewdriverRefill:   2,
+		LastStandsPerLevel:  1,
+		LastStandBonusEvery: 5,
+
+		MaxLevel: 10,
+
+		CrashPoints:       2,
+		ScrewdriverPoints: 5,
+		ArmourPoints:      1,
+		EmperorPoints:     50,
+		LevelBonus:        10,
+		LastStandBonus:    50,
+	}
+}
+
+// BossLevel reports whether the Emperor appears on the given level
+func (r Rules) BossLevel(level int) bool {
+	return r.BossEvery > 0 && level%r.BossEvery == 0
+}
+
+// levelDaleks returns the daleks BaseDaleks and DaleksPerLevel ask for on
+// the given level, before any limit. Levels past maxRuleDaleks ask for no
+// more, as no board could hold them anyway.
+func (r Rules) levelDaleks(level int) int {
+	return r.BaseDaleks + r.DaleksPerLevel*min(level, maxRuleDaleks)
+}
+
+// DalekCount returns the number of daleks placed on the given level
+func (r Rules) DalekCount(level int) int {
+	n := r.levelDaleks(level)
+	if r.MaxDaleks > 0 {
+		n = min(n, r.MaxDaleks)
+	}
+	if limit := r.densityLimit(); limit > 0 {
+		n = min(n, limit)
+	}
+	return n
+}
+
+// densityLimit returns the most daleks MaxDensity allows, 0 for no limit
+func (r Rules) densityLimit() int {
+	if r.MaxDensity <= 0 {
+		return 0
+	}
+	return max(r.Width*r.Height*r.MaxDensity/100, 1)
+}
+
+// RingDaleks returns how many of a level's daleks start in a ring around
+// the player. Once MaxDensity is reached, each level moves the daleks it
+// would have added into the ring instead, up to half of them.
+func (r Rules) RingDaleks(level int) int {
+	limit := r.densityLimit()
+	if limit == 0 {
+		return 0
+	}
+	overflow := r.levelDaleks(level) - limit
+	return min(max(overflow, 0), r.DalekCount(level)/2)
+}
+
+// RingRadius returns how far from the player the ring of daleks starts.
+// The ring closes in the deeper the level is past MaxDensity.
+func (r Rules) RingRadius(level int) int {
+	limit := r.densityLimit()
+	if limit == 0 || r.DaleksPerLevel <= 0 {
+		return ringStartRadius
+	}
+
+	// Levels since the density limit was reached
+	depth := max(r.levelDaleks(level)-limit, 0) / r.DaleksPerLevel
+	return max(ringStartRadius-depth/ringCloseEvery, ringMinRadius)
+}
+
+// taper reduces a per-level refill for the given level
+func (r Rules) taper(refill, level int) int {
+	if r.RefillTaperEvery <= 0 || refill <= 1 {
+		return refill
+	}
+	return max(refill-(level-1)/r.RefillTaperEvery, 1)
+}
+
+// SpawnCapacity returns how many cells are always free for daleks at the
+// start of a level, wherever the player lands
+func (r Rules) SpawnCapacity() int {
+	// Cells too close to a player standing in the middle of the board
+	excluded := 0
+	for dx := -r.Width; dx <= r.Width; dx++ {
+		for dy := -r.Height; dy <= r.Height; dy++ {
+			if dx*dx+dy*dy <= r.MinSpawnDistance {
+				excluded++
+			}
+		}
+	}
+	return max(r.Width*r.Height-excluded, 0)
+}
+
+// Validate reports every setting that is out of range
+func (r Rules) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(len(r.Name) <= maxRulesName, "name must be at most %d characters", maxRulesName)
+	check(r.Width >= minBoardSize && r.Width <= maxBoardSize, "width must be between %d and %d (got %d)", minBoardSize, maxBoardSize, r.Width)
+	check(r.Height >= minBoardSize && r.Height <= maxBoardSize, "height must be between %d and %d (got %d)", minBoardSize, maxBoardSize, r.Height)
+
+	nonNegative := map[string]int{
+		"startTeleports":       r.StartTeleports,
+		"startSafeTeleports":   r.StartSafeTeleports,
+		"startScrewdrivers":    r.StartScrewdrivers,
+		"startLastStands":      r.StartLastStands,
+		"baseDaleks":           r.BaseDaleks,
+		"daleksPerLevel":       r.DaleksPerLevel,
+		"minSpawnDistance":     r.MinSpawnDistance,
+		"maxDaleks":            r.MaxDaleks,
+		"scrapHeaps":           r.ScrapHeaps,
+		"bossEvery":            r.BossEvery,
+		"summonEvery":          r.SummonEvery,
+		"summons":              r.Summons,
+		"maxTurns":             r.MaxTurns,
+		"refillTaperEvery":     r.RefillTaperEvery,
+		"safeTeleportDistance": r.SafeTeleportDistance,
+		"teleportRefill":       r.TeleportRefill,
+		"safeTeleportRefill":   r.SafeTeleportRefill,
+		"screwdriverRecharge":  r.ScrewdriverRecharge,
+		"screwdriverRefill":    r.ScrewdriverRefill,
+		"lastStandsPerLevel":   r.LastStandsPerLevel,
+		"lastStandBonusEvery":  r.LastStandBonusEvery,
+		"crashPoints":          r.CrashPoints,
+		"screwdriverPoints":    r.ScrewdriverPoints,
+		"armourPoints":         r.ArmourPoints,
+		"emperorPoints":        r.EmperorPoints,
+		"levelBonus":           r.LevelBonus,
+		"lastStandBonus":       r.LastStandBonus,
+		"waitBonus":            r.WaitBonus,
+	}
+	for _, name := range slices.Sorted(maps.Keys(nonNegative)) {
+		check(nonNegative[name] >= 0, "%s must not be negative (got %d)", name, nonNegative[name])
+	}
+
+	check(r.BaseDaleks <= maxRuleDaleks, "baseDaleks must be at most %d (got %d)", maxRuleDaleks, r.BaseDaleks)
+	check(r.DaleksPerLevel <= maxRuleDaleks, "daleksPerLevel must be at most %d (got %d)", maxRuleDaleks, r.DaleksPerLevel)
+	check(r.MaxLevel >= 0, "maxLevel must not be negative (got %d)", r.MaxLevel)
+	check(r.BossEvery == 0 || r.EmperorHealth >= 1, "emperorHealth must be at least 1 when there are boss levels (got %d)", r.EmperorHealth)
+	errs = append(errs, r.validateSpawns()...)
+	errs = append(errs, r.validateBrains()...)
+	if r.Layout != nil {
+		errs = append(errs, r.validateLayout()...)
+		return errors.Join(errs...)
+	}
+
+	check(r.MaxDensity >= 0 && r.MaxDensity <= 100, "maxDensity must be between 0 and 100 (got %d)", r.MaxDensity)
+	check(r.MaxLevel > 0 || r.MaxDaleks > 0 || r.MaxDensity > 0, "maxDaleks or maxDensity must be set when there is no final level (maxLevel 0)")
+	check(r.DalekCount(1) >= 1, "level 1 must have at least one dalek (baseDaleks + daleksPerLevel is %d)", r.DalekCount(1))
+
+	if len(errs) == 0 {
+		// Scrap heaps can take any of the cells daleks could use, and the
+		// Emperor takes three more cells than the dalek it replaces
+		capacity := r.SpawnCapacity() - r.ScrapHeaps
+		if r.BossEvery > 0 {
+			capacity -= DalekEmperor.Size()*DalekEmperor.Size() - 1
+		}
+		if r.MaxLevel > 0 {
+			check(r.DalekCount(r.MaxLevel) <= capacity,
+				"level %d needs %d daleks but only %d cells are far enough from the player", r.MaxLevel, r.DalekCount(r.MaxLevel), capacity)
+		} else {
+			deepest := r.DalekCount(math.MaxInt32)
+			check(deepest <= capacity,
+				"deep levels need %d daleks but only %d cells are far enough from the player", deepest, capacity)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateSpawns checks that every spawn is for a special kind of dalek,
+// once each, with a chance that makes sense
+func (r Rules) validateSpawns() []error {
+	var errs []error
+	seen := make(map[DalekKind]bool)
+	for i, sp := range r.Spawns {
+		add := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("spawns %d: "+format, append([]any{i + 1}, args...)...))
+		}
+		switch sp.Kind {
+		case DalekNormal:
+			add("daleks are normal unless a spawn says otherwise, so kind must not be normal")
+		case DalekEmperor:
+			add("the Emperor only appears on boss levels, set by bossEvery")
+		}
+		if seen[sp.Kind] {
+			add("kind %s is listed twice", sp.Kind)
+		}
+		seen[sp.Kind] = true
+		if sp.From < 1 {
+			add("from must be at least 1 (got %d)", sp.From)
+		}
+		if sp.Weight < 0 || sp.Weight > 100 {
+			add("weight must be between 0 and 100 (got %d)", sp.Weight)
+		}
+		if sp.PerLevel < 0 {
+			add("perLevel must not be negative (got %d)", sp.PerLevel)
+		}
+		if sp.Max != 0 && (sp.Max < sp.Weight || sp.Max > 100) {
+			add("max must be between weight and 100 (got %d)", sp.Max)
+		}
+	}
+
+	// Games can start on any level, so every spawn may be at its peak
+	total := 0
+	for _, sp := range r.Spawns {
+		total += sp.peak()
+	}
+	if total > 100 {
+		errs = append(errs, fmt.Errorf("spawns can add up to a %d%% chance, more than 100%% (lower weight, or set max on spawns that grow)", total))
+	}
+	return errs
+}
+
+// validateBrains checks that every brain exists and that level brains are
+// listed in level order
+func (r Rules) validateBrains() []error {
+	var errs []error
+	known := func(name string) bool {
+		return slices.Contains(BrainNames(), name)
+	}
+
+	if r.Brain != "" && !known(r.Brain) {
+		errs = append(errs, fmt.Errorf("brain %q is unknown (choose from %s)", r.Brain, strings.Join(BrainNames(), ", ")))
+	}
+	for _, kind := range slices.Sorted(maps.Keys(r.KindBrains)) {
+		if kind == DalekEmperor {
+			errs = append(errs, errors.New("kindBrains: the Emperor always makes its own way to the player"))
+		}
+		if name := r.KindBrains[kind]; !known(name) {
+			errs = append(errs, fmt.Errorf("kindBrains %s: brain %q is unknown (choose from %s)", kind, name, strings.Join(BrainNames(), ", ")))
+		}
+	}
+	for i, lb := range r.LevelBrains {
+		if !known(lb.Brain) {
+			errs = append(errs, fmt.Errorf("levelBrains %d: brain %q is unknown (choose from %s)", i+1, lb.Brain, strings.Join(BrainNames(), ", ")))
+		}
+		if lb.From < 1 || (i > 0 && lb.From <= r.LevelBrains[i-1].From) {
+			errs = append(errs, fmt.Errorf("levelBrains %d: from must be at least 1 and after the previous entry (got %d)", i+1, lb.From))
+		}
+	}
+	return errs
+}
+
+// validateLayout checks that a hand-placed board fits and can be played
+func (r Rules) validateLayout() []error {
+	var errs []error
+	l := r.Layout
+	inBounds := func(p Position) bool {
+		return p.X >= 0 && p.X < r.Width && p.Y >= 0 && p.Y < r.Height
+	}
+
+	if !inBounds(l.Player) {
+		errs = append(errs, fmt.Errorf("layout: player at %d,%d is off the board", l.Player.X, l.Player.Y))
+	}
+	if len(l.Daleks)+len(l.FastDaleks)+len(l.ArmouredDaleks) == 0 {
+		errs = append(errs, errors.New("layout: needs at least one dalek"))
+	}
+
+	used := map[Position]string{l.Player: "the player"}
+	place := func(what string, p Position) {
+		switch {
+		case !inBounds(p):
+			errs = append(errs, fmt.Errorf("layout: %s at %d,%d is off the board", what, p.X, p.Y))
+		case used[p] != "":
+			errs = append(errs, fmt.Errorf("layout: %s at %d,%d is on top of %s", what, p.X, p.Y, used[p]))
+		default:
+			used[p] = "a " + what
+		}
+	}
+	for _, p := range l.Daleks {
+		place("dalek", p)
+	}
+	for _, p := range l.FastDaleks {
+		place("fast dalek", p)
+	}
+	for _, p := range l.ArmouredDaleks {
+		place("armoured dalek", p)
+	}
+	for _, p := range l.Scraps {
+		place("scrap heap", p)
+	}
+	return errs
+}
+
+// rulesFile is the on-disk form of Rules. It allows a "$schema" key so
+// editors can validate the file against RulesSchema.
+type rulesFile struct {
+	Schema string `json:"$schema,omitempty"`
+	Rules
+}
+
+// ParseRules decodes and validates JSON rules. Settings missing from the
+// data keep their default values, except the name which defaults to
+// "Custom"; unknown settings are an error.
+func ParseRules(data []byte) (Rules, error) {
+	file := rulesFile{Rules: DefaultRules()}
+	file.Name = ""
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return file.Rules, describeJSONError(data, dec.InputOffset(), err)
+	}
+	if dec.More() {
+		return file.Rules, errors.New("unexpected data after the rules object")
+	}
+
+	if file.Name == "" {
+		file.Name = "Custom"
+	}
+	if err := file.Rules.Validate(); err != nil {
+		return file.Rules, fmt.Errorf("invalid rules:\n%w", err)
+	}
+	return file.Rules, nil
+}
+
+// LoadRules reads a JSON rules file. Settings missing from the file keep
+// their default values.
+func LoadRules(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return DefaultRules(), err
+	}
+	return ParseRules(data)
+}
+
+// MarshalRules encodes rules as an indented JSON rules file
+func MarshalRules(r Rules) ([]byte, error) {
+	return json.MarshalIndent(rulesFile{Schema: rulesSchemaID, Rules: r}, "", "  ")
+}
+
+// describeJSONError adds the line and column to JSON decoding errors
+func describeJSONError(data []byte, offset int64, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, col := lineColumn(data, syntaxErr.Offset)
+		return fmt.Errorf("line %d, column %d: %v", line, col, syntaxErr)
+	case errors.As(err, &typeErr):
+		line, col := lineColumn(data, typeErr.Offset)
+		return fmt.Errorf("line %d, column %d: %s must be %s, not %s", line, col, typeErr.Field, typeErr.Type, typeErr.Value)
+	case errors.Is(err, io.EOF):
+		return errors.New("rules file is empty")
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		line, col := lineColumn(data, offset)
+		return fmt.Errorf("line %d, column %d: unknown setting %s", line, col, strings.TrimPrefix(err.Error(), "json: unknown field "))
+	}
+	return err
+}
+
+// lineColumn converts a byte offset into a 1-based line and column
+func lineColumn(data []byte, offset int64) (int, int) {
+	offset = min(offset, int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPresetsValidate(t *testing.T) {
+	for _, p := range Presets() {
+		t.Run(p.Name, func(t *testing.T) {
+			if err := p.Rules.Validate(); err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+			if p.Rules.Name != p.Name {
+				t.Errorf("rules are named %q", p.Rules.Name)
+			}
+
+			rules, err := PresetRules(strings.ToUpper(p.Name))
+			if err != nil || rules.Name != p.Name {
+				t.Errorf("PresetRules(%q) gave %q, %v", strings.ToUpper(p.Name), rules.Name, err)
+			}
+		})
+	}
+
+	if _, err := PresetRules("impossible"); err == nil {
+		t.Errorf("PresetRules accepted an unknown preset")
+	}
+}
+
+func TestRulesFileRoundTrip(t *testing.T) {
+	for _, p := range Presets() {
+		t.Run(p.Name, func(t *testing.T) {
+			data, err := MarshalRules(p.Rules)
+			if err != nil {
+				t.Fatalf("MarshalRules: %v", err)
+			}
+			rules, err := ParseRules(data)
+			if err != nil {
+				t.Fatalf("ParseRules: %v", err)
+			}
+			again, _ := MarshalRules(rules)
+			if string(again) != string(data) {
+				t.Errorf("round trip gave\n%s\nwant\n%s", again, data)
+			}
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string // Part of the error, empty for none
+	}{
+		{"defaults", `{}`, ""},
+		{"schema key", `{"$schema": "rules.schema.json", "name": "Mine"}`, ""},
+		{"unknown setting", `{"teleprots": 3}`, "teleprots"},
+		{"wrong type", `{"width": "wide"}`, "width"},
+		{"trailing data", `{} {}`, "unexpected data"},
+		{"width too small", `{"width": 2}`, "width must be between"},
+		{"negative", `{"crashPoints": -1}`, "crashPoints must not be negative"},
+		{"unknown brain", `{"brain": "clever"}`, "brain \"clever\" is unknown"},
+		{"normal spawn", `{"spawns": [{"kind": "normal", "from": 1, "weight": 10}]}`, "kind must not be normal"},
+		{"spawns past 100", `{"spawns": [{"kind": "fast", "from": 1, "weight": 10, "perLevel": 5}, {"kind": "armoured", "from": 1, "weight": 10}]}`, "more than 100%"},
+		{"spawns within 100", `{"spawns": [{"kind": "fast", "from": 1, "weight": 10, "perLevel": 5, "max": 60}, {"kind": "armoured", "from": 1, "weight": 40}]}`, ""},
+		{"max below weight", `{"spawns": [{"kind": "fast", "from": 1, "weight": 10, "perLevel": 5, "max": 5}]}`, "max must be between"},
+		{"emperor spawn", `{"spawns": [{"kind": "emperor", "from": 1, "weight": 10}]}`, "boss levels"},
+		{"emperor brain", `{"kindBrains": {"emperor": "greedy"}}`, "makes its own way"},
+		{"no emperor health", `{"emperorHealth": 0}`, "emperorHealth"},
+		{"too many daleks", `{"baseDaleks": 2000}`, "cells are far enough"},
+		{"base daleks past the limit", `{"baseDaleks": 40001}`, "baseDaleks must be at most 40000"},
+		{"daleks per level past the limit", `{"daleksPerLevel": 2147483647, "maxLevel": 0, "maxDaleks": 30}`, "daleksPerLevel must be at most 40000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseRules([]byte(tt.data))
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("ParseRules: %v", err)
+			case tt.want == "" && rules.Name == "":
+				t.Errorf("rules have no name")
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDalekCount(t *testing.T) {
+	rules := DefaultRules()
+	rules.MaxDaleks = 12
+	tests := []struct {
+		level int
+		want  int
+	}{
+		{1, 6},
+		{5, 10},
+		{7, 12},
+		{50, 12},
+		{math.MaxInt32, 12},
+	}
+
+	for _, tt := range tests {
+		if got := rules.DalekCount(tt.level); got != tt.want {
+			t.Errorf("DalekCount(%d) = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+
+	// Deep levels must not overflow, even with 32-bit ints
+	rules.DaleksPerLevel = maxRuleDaleks
+	rules.MaxDaleks = 0
+	if got := rules.DalekCount(math.MaxInt32); got < maxRuleDaleks {
+		t.Errorf("DalekCount(MaxInt32) = %d with %d daleks per level", got, maxRuleDaleks)
+	}
+}
+
+func TestSpawnWeightAt(t *testing.T) {
+	tests := []struct {
+		name  string
+		spawn Spawn
+		level int
+		want  int
+	}{
+		{"before from", Spawn{From: 3, Weight: 10, PerLevel: 40}, 1, 0},
+		{"on from", Spawn{From: 3, Weight: 10, PerLevel: 40}, 3, 10},
+		{"growing", Spawn{From: 3, Weight: 10, PerLevel: 40}, 4, 50},
+		{"no max", Spawn{From: 3, Weight: 10, PerLevel: 40}, 6, 100},
+		{"max", Spawn{From: 3, Weight: 10, PerLevel: 40, Max: 30}, 6, 30},
+		{"flat", Spawn{From: 3, Weight: 10}, 60, 10},
+	}
+
+	for _, tt := range tests {
+		if got := tt.spawn.WeightAt(tt.level); got != tt.want {
+			t.Errorf("%s: WeightAt(%d) = %d, want %d", tt.name, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestSpawnsKeepNormalDaleks(t *testing.T) {
+	// Deep levels of every preset still place normal daleks
+	for _, p := range Presets() {
+		t.Run(p.Name, func(t *testing.T) {
+			for _, level := range []int{20, 60, 200} {
+				total := 0
+				for _, sp := range p.Rules.Spawns {
+					total += sp.WeightAt(level)
+				}
+				if total >= 100 {
+					t.Errorf("level %d: special daleks have a %d%% chance", level, total)
+				}
+			}
+		})
+	}
+}
+
+func TestBrainFor(t *testing.T) {
+	rules := DefaultRules()
+	rules.LevelBrains = []LevelBrain{{From: 3, Brain: BrainPathfinding}, {From: 6, Brain: BrainFlanking}}
+	tests := []struct {
+		level int
+		want  string
+	}{
+		{1, BrainGreedy},
+		{3, BrainPathfinding},
+		{5, BrainPathfinding},
+		{9, BrainFlanking},
+	}
+
+	for _, tt := range tests {
+		if got := rules.BrainFor(tt.level); got != tt.want {
+			t.Errorf("BrainFor(%d) = %q, want %q", tt.level, got, tt.want)
+		}
+	}
+}
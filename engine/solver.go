@@ -0,0 +1,199 @@
+package engine
+
+// HintDepth is how many turns ahead BestAction looks by default
+const HintDepth = 4
+
+const (
+	itemCost      = 0.05 // Progress given up by spending an item, so free moves win ties
+	clearProgress = 2    // Progress credited for clearing the level
+	survivalSlack = 1e-9 // Survival chances closer than this count as equal
+	yieldEvery    = 128  // Positions searched between calls to a yield function
+)
+
+// Hint is the solver's advice for a position
+type Hint struct {
+	Action     Action
+	Survival   float64 // Chance that Action survives the search horizon or clears the level
+	ForcedLoss bool    // Nothing survives the horizon, not even a lucky teleport
+	Depth      int     // Turns searched
+}
+
+// outcome is the value of a position to the player: the chance of
+// surviving, then how much progress was made as a tie-break
+type outcome struct {
+	survival float64
+	progress float64
+}
+
+func (o outcome) betterThan(other outcome) bool {
+	if o.survival > other.survival+survivalSlack {
+		return true
+	}
+	return o.survival >= other.survival-survivalSlack && o.progress > other.progress
+}
+
+// searchActions are the deterministic actions the solver tries every turn:
+// waiting, the eight moves and the screwdriver
+var searchActions = []Action{
+	{Kind: ActionWait},
+	MoveAction(0, -1), MoveAction(0, 1), MoveAction(-1, 0), MoveAction(1, 0),
+	MoveAction(-1, -1), MoveAction(1, -1), MoveAction(-1, 1), MoveAction(1, 1),
+	{Kind: ActionScrewdriver},
+}
+
+// solver holds what an expectimax search needs to know about its root
+type solver struct {
+	level  int // Level being searched; leaving it means it was cleared
+	daleks int // Daleks at the root, to measure progress
+
+	// Chance a teleport from the root survives its first turn. Deeper in
+	// the search, where enumerating every landing cell is too costly,
+	// teleports are assumed to have the same odds.
+	teleportOdds     float64
+	safeTeleportOdds float64
+
+	yield func() // Called every yieldEvery positions, if set
+	nodes int    // Positions searched so far
+}
+
+// BestAction searches depth turns ahead for the action most likely to keep
+// the player alive. Moves, waiting and the screwdriver are searched
+// exactly. Teleports are scored by the odds of every landing cell, as the
+// destination is random.
+func BestAction(s State, depth int) Hint {
+	return BestActionYielding(s, depth, nil)
+}
+
+// BestActionYielding is BestAction for callers that cannot leave the search
+// running alongside the game, such as the browser's single thread. It calls
+// yield every few hundred positions so the caller can hand control back.
+func BestActionYielding(s State, depth int, yield func()) Hint {
+	sv := solver{level: s.Level, daleks: len(s.Daleks), yield: yield}
+	sv.teleportOdds = sv.teleportOutcome(s, false, 0).survival
+	sv.safeTeleportOdds = sv.teleportOutcome(s, true, 0).survival
+
+	hint := Hint{Action: Action{Kind: ActionWait}, Depth: depth}
+	best := outcome{survival: -1}
+	consider := func(a Action, o outcome) {
+		if o.betterThan(best) {
+			best = o
+			hint.Action = a
+		}
+	}
+
+	for _, a := range searchActions {
+		if next, events := Step(s, a); events != nil {
+			consider(a, sv.value(next, depth-1))
+		}
+	}
+	if s.canTeleport(false) {
+		consider(Action{Kind: ActionTeleport}, sv.teleportOutcome(s, false, depth-1))
+	}
+	if s.canTeleport(true) {
+		consider(Action{Kind: ActionSafeTeleport}, sv.teleportOutcome(s, true, depth-1))
+	}
+
+	hint.Survival = max(best.survival, 0)
+	hint.ForcedLoss = hint.Survival == 0
+	return hint
+}
+
+// value is the best outcome reachable from s within depth more turns
+func (sv *solver) value(s State, depth int) outcome {
+	if sv.nodes++; sv.yield != nil && sv.nodes%yieldEvery == 0 {
+		sv.yield()
+	}
+
+	switch {
+	case s.Phase == PhaseGameOver:
+		return outcome{}
+	case s.Phase == PhaseWin || s.Level != sv.level:
+		return outcome{survival: 1, progress: clearProgress}
+	case depth <= 0:
+		return outcome{survival: 1, progress: sv.progress(s)}
+	}
+
+	best := outcome{}
+	for _, a := range searchActions {
+		next, events := Step(s, a)
+		if events == nil {
+			continue
+		}
+		o := sv.value(next, depth-1)
+		if a.Kind == ActionScrewdriver {
+			o.progress -= itemCost
+		}
+		if o.betterThan(best) {
+			best = o
+		}
+	}
+
+	// A teleport is the way out when every other action is caught
+	if best.survival < 1 {
+		progress := sv.progress(s) - itemCost
+		if o := (outcome{sv.teleportOdds, progress}); s.canTeleport(false) && o.betterThan(best) {
+			best = o
+		}
+		if o := (outcome{sv.safeTeleportOdds, progress}); s.canTeleport(true) && o.betterThan(best) {
+			best = o
+		}
+	}
+	return best
+}
+
+// teleportOutcome averages the outcome of a teleport over every cell it
+// could land on, searching depth turns beyond the landing turn
+func (sv *solver) teleportOutcome(s State, safe bool, depth int) outcome {
+	var total outcome
+	cells := 0
+
+	for y := 0; y < s.Rules.Height; y++ {
+		for x := 0; x < s.Rules.Width; x++ {
+			pos := Position{X: x, Y: y}
+			if s.PositionOccupied(pos) || (safe && !s.IsSafePosition(pos)) {
+				continue
+			}
+
+			next := s.Clone()
+			next.Player = pos
+			if safe {
+				next.SafeTeleports--
+			} else if !next.Rules.UnlimitedTeleports {
+				next.Teleports--
+			}
+			var events []Event
+			next.takeTurn(&events)
+
+			// Teleports deeper in the search use the root odds, so only
+			// look one turn past the landing here
+			o := sv.value(next, min(depth, 1))
+			total.survival += o.survival
+			total.progress += o.progress
+			cells++
+		}
+	}
+
+	if cells == 0 {
+		return outcome{}
+	}
+	return outcome{
+		survival: total.survival / float64(cells),
+		progress: total.progress/float64(cells) - itemCost,
+	}
+}
+
+// progress measures how many of the root's daleks have been destroyed
+func (sv *solver) progress(s State) float64 {
+	if sv.daleks == 0 {
+		return 0
+	}
+	return float64(sv.daleks-len(s.Daleks)) / float64(sv.daleks)
+}
+
+// canTeleport reports whether the player has a teleport of the given kind
+func (s State) canTeleport(safe bool) bool {
+	if safe {
+		return s.SafeTeleports > 0
+	}
+	return s.Teleports > 0 || s.Rules.UnlimitedTeleports
+}
@@ -0,0 +1,89 @@
+package engine
+
+import "testing"
+
+func TestBestAction(t *testing.T) {
+	tests := []struct {
+		name           string
+		board          string
+		wantAction     *Action // nil when any surviving action will do
+		wantForcedLoss bool
+	}{
+		{
+			name: "step away",
+			board: `
+				Teleports: 0  Safe: 0  Screwdrivers: 0  LastStands: 0
+				.......
+				.......
+				..+....
+				...@...
+				.......
+				.......
+				......+`,
+		},
+		{
+			name: "screwdriver when surrounded",
+			board: `
+				Teleports: 0  Safe: 0  Screwdrivers: 1  LastStands: 0
+				.....
+				.+++.
+				.+@+.
+				.+++.
+				.....`,
+			wantAction: &Action{Kind: ActionScrewdriver},
+		},
+		{
+			name: "nothing left",
+			board: `
+				Teleports: 0  Safe: 0  Screwdrivers: 0  LastStands: 0
+				.....
+				.+++.
+				.+@+.
+				.+++.
+				.....`,
+			wantForcedLoss: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := parseBoard(t, DefaultRules(), tt.board)
+			hint := BestAction(s, 3)
+
+			if hint.ForcedLoss != tt.wantForcedLoss {
+				t.Fatalf("forced loss %v, want %v (survival %g)", hint.ForcedLoss, tt.wantForcedLoss, hint.Survival)
+			}
+			if tt.wantForcedLoss {
+				return
+			}
+			if tt.wantAction != nil && hint.Action != *tt.wantAction {
+				t.Errorf("action %+v, want %+v", hint.Action, *tt.wantAction)
+			}
+			if next, events := Step(s, hint.Action); events == nil || next.Phase == PhaseGameOver {
+				t.Errorf("advised action %+v does not survive", hint.Action)
+			}
+		})
+	}
+}
+
+func TestBestActionDoesNotChangeState(t *testing.T) {
+	s, _ := NewState(DefaultRules(), 21)
+	before := FormatASCII(s)
+	BestAction(s, 2)
+	if after := FormatASCII(s); after != before {
+		t.Errorf("BestAction changed the state:\n%s\n%s", before, after)
+	}
+}
+
+func TestBestActionYielding(t *testing.T) {
+	s, _ := NewStateAt(DefaultRules(), 4, 3)
+	yields := 0
+	got := BestActionYielding(s, 2, func() { yields++ })
+
+	if want := BestAction(s, 2); got != want {
+		t.Errorf("yielding search gave %+v, want %+v", got, want)
+	}
+	if yields == 0 {
+		t.Errorf("search never yielded")
+	}
+}
@@ -0,0 +1,41 @@
+// Package levels holds the level packs and puzzles that ship with the game.
+// It only depends on the engine, so tools that run without a window, such
+// as replay verification, can load them too.
+package levels
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+// PackFiles holds the built-in level packs, one JSON file each under packs/
+//
+//go:embed packs/*.json
+var PackFiles embed.FS
+
+//go:embed puzzles.json
+var puzzleData []byte
+
+// Packs returns the level packs that ship with the game. Packs that fail to
+// parse are left out.
+func Packs() []*engine.Pack {
+	var packs []*engine.Pack
+	files, _ := fs.Glob(PackFiles, "packs/*.json")
+	for _, file := range files {
+		data, err := PackFiles.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if pack, err := engine.ParsePack(data); err == nil {
+			packs = append(packs, pack)
+		}
+	}
+	return packs
+}
+
+// Puzzles returns the puzzles that ship with the game
+func Puzzles() ([]engine.Puzzle, error) {
+	return engine.ParsePuzzles(puzzleData)
+}
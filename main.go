@@ -1,9 +1,15 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
 
 	"github.com/AaronSaikovski/godaleks/daleks"
+	"github.com/AaronSaikovski/godaleks/engine"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -12,12 +18,81 @@ const (
 	screenHeight = 600
 )
 
+// parseFlags - Parses the command line into a game config
+func parseFlags() (daleks.Config, error) {
+	cfg := daleks.DefaultConfig()
+
+	flag.Func("seed", "start a game immediately with this seed", func(s string) error {
+		seed, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		cfg.Seed = seed
+		cfg.HasSeed = true
+		return nil
+	})
+	flag.IntVar(&cfg.Level, "level", cfg.Level, "level new games start on")
+	flag.StringVar(&cfg.Preset, "preset", cfg.Preset, "rules preset: easy, normal, hard, nightmare, endless, robots or classic")
+	flag.StringVar(&cfg.RulesFile, "rules", "", "JSON rules file (see godaleks rules)")
+	flag.BoolVar(&cfg.Mute, "mute", false, "run without audio")
+	flag.BoolVar(&cfg.Fullscreen, "fullscreen", false, "start in fullscreen")
+	flag.Float64Var(&cfg.Scale, "scale", cfg.Scale, "window scale factor")
+	flag.BoolVar(&cfg.ShowGrid, "grid", false, "show the grid overlay")
+	flag.StringVar(&cfg.ReplayFile, "replay", "", "play back a replay file")
+	flag.StringVar(&cfg.RecordFile, "record", "", "also write the replay of each game to this file")
+	flag.StringVar(&cfg.BoardFile, "board", "", "start playing a text board (as copied with B)")
+
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: godaleks [flags]\n       godaleks verify [--score N] [--preset name | --rules rules.json] [--pack pack.json] run.replay\n       godaleks rules [--schema] [rules.json]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if cfg.Level < 1 {
+		return cfg, fmt.Errorf("--level must be at least 1, got %d", cfg.Level)
+	}
+	if _, err := engine.PresetRules(cfg.Preset); err != nil {
+		return cfg, fmt.Errorf("--preset: %w", err)
+	}
+	presetSet := false
+	flag.Visit(func(f *flag.Flag) { presetSet = presetSet || f.Name == "preset" })
+	if presetSet && cfg.RulesFile != "" {
+		return cfg, errors.New("--preset and --rules cannot be used together")
+	}
+	if cfg.Scale <= 0 {
+		return cfg, fmt.Errorf("--scale must be positive, got %g", cfg.Scale)
+	}
+	return cfg, nil
+}
+
 // main - Main function
 func main() {
 
-	game := daleks.NewGame()
-	ebiten.SetWindowSize(screenWidth, screenHeight)
+	// Headless subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "verify":
+			os.Exit(runVerify(os.Args[2:]))
+		case "rules":
+			os.Exit(runRules(os.Args[2:]))
+		}
+	}
+
+	cfg, err := parseFlags()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	game, err := daleks.NewGame(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ebiten.SetWindowSize(int(screenWidth*cfg.Scale), int(screenHeight*cfg.Scale))
 	ebiten.SetWindowTitle("GoDaleks")
+	ebiten.SetWindowClosingHandled(true) // Lets the game auto-save on quit
+	ebiten.SetFullscreen(cfg.Fullscreen)
 
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+)
+
+// runRules - Prints the default rules, the rules schema, or the checked
+// contents of a rules file. It returns the process exit code.
+func runRules(args []string) int {
+	flags := flag.NewFlagSet("rules", flag.ContinueOnError)
+	schema := flags.Bool("schema", false, "print the JSON Schema for rules files")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: godaleks rules [--schema] [rules.json]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	if *schema {
+		os.Stdout.Write(engine.RulesSchema)
+		return 0
+	}
+
+	rules := engine.DefaultRules()
+	if flags.NArg() == 1 {
+		var err error
+		rules, err = engine.LoadRules(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", flags.Arg(0), err)
+			return 1
+		}
+	}
+
+	data, err := engine.MarshalRules(rules)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Println(string(data))
+	return 0
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AaronSaikovski/godaleks/engine"
+	"github.com/AaronSaikovski/godaleks/levels"
+)
+
+// runVerify - Re-simulates a replay without opening a window and reports
+// whether its claimed result holds. It returns the process exit code.
+func runVerify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	claimed := flags.Int("score", -1, "score claimed for the replay (defaults to the score stored in it)")
+	preset := flags.String("preset", "", "only accept replays played with this preset")
+	rulesFile := flags.String("rules", "", "only accept replays played with this JSON rules file")
+	packFile := flags.String("pack", "", "accept replays played through this level pack")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: godaleks verify [--score N] [--preset name | --rules rules.json] [--pack pack.json] run.replay")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	if *preset != "" && *rulesFile != "" {
+		fmt.Fprintln(os.Stderr, "--preset and --rules cannot be used together")
+		return 2
+	}
+
+	trusted, err := trustedRules(*preset, *rulesFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	packs := levels.Packs()
+	if *packFile != "" {
+		data, err := os.ReadFile(*packFile)
+		var pack *engine.Pack
+		if err == nil {
+			pack, err = engine.ParsePack(data)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *packFile, err)
+			return 2
+		}
+		packs = []*engine.Pack{pack}
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	replay, err := engine.ReadReplay(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flags.Arg(0), err)
+		return 2
+	}
+
+	if *claimed >= 0 {
+		replay.Score = *claimed
+	}
+
+	// Only known rules prove anything: a replay can claim any rules at all
+	name, err := matchRules(replay, trusted, packs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verification failed: %v\n", err)
+		return 1
+	}
+
+	final, err := replay.Verify()
+	fmt.Printf("rules:   %s\n", name)
+	fmt.Printf("seed:    %d\n", replay.Seed)
+	fmt.Printf("start:   level %d\n", max(replay.StartLevel, 1))
+	fmt.Printf("actions: %d\n", len(replay.Actions))
+	fmt.Printf("level:   %d\n", final.Level)
+	fmt.Printf("score:   %d\n", final.Score)
+	fmt.Printf("outcome: %s\n", final.Phase)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verification failed: %v\n", err)
+		return 1
+	}
+	fmt.Printf("verified (%s)\n", name)
+	return 0
+}
+
+// trustedRules returns the rules a replay may have been played with: the
+// preset or rules file asked for, or else every preset, the daily rules and
+// the built-in puzzles
+func trustedRules(preset, rulesFile string) ([]engine.Preset, error) {
+	switch {
+	case preset != "":
+		rules, err := engine.PresetRules(preset)
+		if err != nil {
+			return nil, fmt.Errorf("--preset: %w", err)
+		}
+		return []engine.Preset{{Name: rules.Name, Rules: rules}}, nil
+	case rulesFile != "":
+		rules, err := engine.LoadRules(rulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rulesFile, err)
+		}
+		return []engine.Preset{{Name: rules.Name, Rules: rules}}, nil
+	}
+
+	puzzles, err := levels.Puzzles()
+	if err != nil {
+		return nil, fmt.Errorf("built-in puzzles: %w", err)
+	}
+
+	trusted := engine.Presets()
+	trusted = append(trusted, engine.Preset{Name: "Daily", Rules: engine.DailyRules()})
+	for _, p := range puzzles {
+		trusted = append(trusted, engine.Preset{Name: "Puzzle: " + p.Name, Rules: p.Rules()})
+	}
+	return trusted, nil
+}
+
+// matchRules returns the name of the trusted rules or level pack the replay
+// was played with, preferring the one it is named after when several match
+func matchRules(replay engine.Replay, trusted []engine.Preset, packs []*engine.Pack) (string, error) {
+	if replay.Pack != nil {
+		for _, pack := range packs {
+			if sameJSON(replay.Pack, pack) {
+				return pack.Name, nil
+			}
+		}
+		return "", fmt.Errorf("level pack %q does not match a built-in pack or the one given with --pack", replay.Pack.Name)
+	}
+
+	name := ""
+	for _, p := range trusted {
+		if sameRules(replay.Rules, p.Rules) && (name == "" || strings.EqualFold(p.Name, replay.Rules.Name)) {
+			name = p.Name
+		}
+	}
+	if name == "" {
+		return "", errors.New("rules are not a preset, the daily rules or the rules given with --preset or --rules")
+	}
+	return name, nil
+}
+
+// sameRules reports whether two rule sets play the same game. Names are
+// only shown to the player, so they are not compared.
+func sameRules(a, b engine.Rules) bool {
+	a.Name, b.Name = "", ""
+	return sameJSON(a, b)
+}
+
+// sameJSON reports whether two values encode to the same JSON
+func sameJSON(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}